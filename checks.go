@@ -1,20 +1,45 @@
 //This file contains all the built in checks/validators for teh built in rules like
-//"required", "time", "int", "unsigned int"
+//"required", "time", "int", "unsigned int", the range rules (e.g. "min", "maxlen") the format rules (e.g. "email")
+//the regular expression rules ("regex", "pattern"), the enumeration rules ("oneof", "oneofci") and the conditional
+//rules (e.g. "required_if", "excluded_with")
+//The checks report their failures as FieldError values
 
 package validator
 
 import (
+	"encoding/base64"
 	"fmt"
+	"net"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+//The type of the durations, measured by their value by the range rules
+var durationType = reflect.TypeOf(time.Duration(0))
+
+//Patterns used by the format rules
+var (
+	uuidPattern          = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameLabelPattern = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	tldPattern           = regexp.MustCompile(`^[a-zA-Z]{2,63}$`)
+	hexPattern           = regexp.MustCompile(`^(0[xX])?[0-9a-fA-F]+$`)
+	semverPattern        = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(-((0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(\.(0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(\+([0-9a-zA-Z-]+(\.[0-9a-zA-Z-]+)*))?$`)
 )
 
 //Validates if, the given key "mapKey" for a given map "m" is present in m
 //First it checks if the mapKey is an empty string and if no it will return an error
 func checkRequired(mapKey string, m map[string]string, params ...string) error {
 	if _, ok := m[mapKey]; !ok {
-		return fmt.Errorf("requred field '%s' is not preset in map", mapKey)
+		return &FieldError{Key: mapKey, Rule: ruleRequired, Params: params,
+			Err: fmt.Errorf("required key '%s' is not present in map", mapKey)}
 	}
 	return nil
 }
@@ -26,7 +51,8 @@ func checkInt(mapKey string, m map[string]string, params ...string) error {
 	if mapValue, ok := m[mapKey]; ok {
 		_, err := strconv.Atoi(mapValue)
 		if err != nil {
-			return fmt.Errorf("failed to convert string to int for key '%s'", mapKey)
+			return &FieldError{Key: mapKey, Rule: ruleInt, Value: mapValue, Params: params,
+				Err: fmt.Errorf("failed to convert string to int for key '%s'", mapKey)}
 		}
 	}
 
@@ -38,12 +64,15 @@ func checkInt(mapKey string, m map[string]string, params ...string) error {
 func checkUnsigned(mapKey string, m map[string]string, params ...string) error {
 	if mapValue, ok := m[mapKey]; ok {
 		if strings.HasPrefix(mapValue, "-"){
-			return fmt.Errorf("map key '%s' does not match constraint '%s'", mapKey, ruleUnsigned)
+			return &FieldError{Key: mapKey, Rule: ruleUnsigned, Value: mapValue, Params: params,
+				Err: fmt.Errorf("map key '%s' does not match constraint '%s'", mapKey, ruleUnsigned)}
 		}
 	}
-	err := checkInt(mapKey, m)
+	err := checkInt(mapKey, m, params...)
 	if err != nil {
-		return err
+		fieldErr := err.(*FieldError)
+		fieldErr.Rule = ruleUnsigned
+		return fieldErr
 	}
 	return nil
 }
@@ -55,7 +84,8 @@ func checkTime(mapKey string, m map[string]string, params ...string) error {
 	if mapValue, ok := m[mapKey]; ok {
 		_, err := time.Parse(time.RFC3339, mapValue)
 		if err != nil {
-			return fmt.Errorf("error checking time string")
+			return &FieldError{Key: mapKey, Rule: ruleTime, Value: mapValue, Params: params,
+				Err: fmt.Errorf("error checking time string: %v", err)}
 		}
 	}
 	return nil
@@ -66,8 +96,629 @@ func checkTime(mapKey string, m map[string]string, params ...string) error {
 func checkBool(mapKey string, m map[string]string, params ...string) error {
 	if mapValue, ok := m[mapKey]; ok {
 		if mapValue != "true" && mapValue != "false" {
-			return fmt.Errorf("error checking bool string")
+			return &FieldError{Key: mapKey, Rule: ruleBool, Value: mapValue, Params: params,
+				Err: fmt.Errorf("error checking bool string")}
+		}
+	}
+	return nil
+}
+
+//Validates if, for a given field, the map value is greater than or equal to the first param
+//Numbers are compared by value, strings by their number of characters and slices by their number of elements
+func checkMin(fc fieldContext, params ...string) error {
+	return checkBounds(fc, ruleMin, params, measureValue, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure >= bounds[0]
+	})
+}
+
+//Validates if, for a given field, the map value is less than or equal to the first param
+//Numbers are compared by value, strings by their number of characters and slices by their number of elements
+func checkMax(fc fieldContext, params ...string) error {
+	return checkBounds(fc, ruleMax, params, measureValue, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure <= bounds[0]
+	})
+}
+
+//Validates if, for a given field, the map value is between the two params (inclusive)
+//Numbers are compared by value, strings by their number of characters and slices by their number of elements
+func checkBetween(fc fieldContext, params ...string) error {
+	return checkBounds(fc, ruleBetween, params, measureValue, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 2 && measure >= bounds[0] && measure <= bounds[1]
+	})
+}
+
+//Validates if, for a given field, the length of the map value is equal to the first param
+//The length of a string is its number of characters and the length of a slice is its number of elements
+func checkLen(fc fieldContext, params ...string) error {
+	return checkBounds(fc, ruleLen, params, measureLength, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure == bounds[0]
+	})
+}
+
+//Validates if, for a given field, the length of the map value is greater than or equal to the first param
+//The length of a string is its number of characters and the length of a slice is its number of elements
+func checkMinLen(fc fieldContext, params ...string) error {
+	return checkBounds(fc, ruleMinLen, params, measureLength, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure >= bounds[0]
+	})
+}
+
+//Validates if, for a given field, the length of the map value is less than or equal to the first param
+//The length of a string is its number of characters and the length of a slice is its number of elements
+func checkMaxLen(fc fieldContext, params ...string) error {
+	return checkBounds(fc, ruleMaxLen, params, measureLength, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure <= bounds[0]
+	})
+}
+
+//Common implementation of the range rules
+//Parses the params as numbers (see parseBounds), measures the map value of the field using "measure" and checks the
+//result with "accept"
+//If the key is not present in the map, the check passes; params that are not numbers are a misconfiguration, reported
+//regardless of the map data
+func checkBounds(fc fieldContext, rule string, params []string,
+	measure func(fc fieldContext, value string) (float64, error),
+	accept func(measure float64, bounds []float64) bool) error {
+	bounds, err := parseBounds(params, fieldType(fc.field) == durationType)
+	if err != nil {
+		return &configError{err: err}
+	}
+	mapValue, ok := fc.m[fc.key]
+	if !ok {
+		return nil
+	}
+
+	measured, err := measure(fc, mapValue)
+	if err != nil {
+		return &FieldError{Key: fc.key, Rule: rule, Value: mapValue, Params: params, Err: err}
+	}
+	if !accept(measured, bounds) {
+		return &FieldError{Key: fc.key, Rule: rule, Value: mapValue, Params: params,
+			Err: fmt.Errorf("measured value %v does not match constraint '%s=%s'", measured, rule,
+				strings.Join(params, "|"))}
+	}
+
+	return nil
+}
+
+//Parses the params of a range rule as numbers
+//When "isDuration" is true the params can be durations as well (e.g. "1m30s"), measured in nanoseconds like the
+//time.Duration values
+func parseBounds(params []string, isDuration bool) ([]float64, error) {
+	bounds := make([]float64, 0, len(params))
+	for _, param := range params {
+		bound, err := strconv.ParseFloat(strings.TrimSpace(param), 64)
+		if err != nil && isDuration {
+			var duration time.Duration
+			duration, err = time.ParseDuration(strings.TrimSpace(param))
+			bound = float64(duration)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rule param '%s' is not a number", param)
+		}
+		bounds = append(bounds, bound)
+	}
+
+	return bounds, nil
+}
+
+//Measures a map value based on the type of the field it is linked to
+//Numeric fields are measured by their value, any other field is measured by its length (see measureLength)
+//The time.Duration fields are measured by their value in nanoseconds (e.g. "1m" -> 6e10)
+func measureValue(fc fieldContext, value string) (float64, error) {
+	if fieldType(fc.field) == durationType {
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert string to duration")
+		}
+		return float64(duration), nil
+	}
+
+	switch fieldType(fc.field).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to convert string to number")
+		}
+		return number, nil
+	default:
+		return measureLength(fc, value)
+	}
+}
+
+//Measures the length of a map value based on the type of the field it is linked to
+//Slice and array fields are measured by their number of elements, map fields by their number of entries and any
+//other field by its number of characters
+func measureLength(fc fieldContext, value string) (float64, error) {
+	switch fieldType(fc.field).Kind() {
+	case reflect.Slice, reflect.Array:
+		return float64(len(fc.lists.split(fc.key, value, fieldSeparator(fc.field)))), nil
+	case reflect.Map:
+		return float64(len(findEntries(fc.m, fc.key))), nil
+	default:
+		return float64(utf8.RuneCountInString(value)), nil
+	}
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an email address (e.g. "john@example.com")
+//Addresses containing a display name (e.g. "John <john@example.com>") are rejected
+func checkEmail(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleEmail, params, "an email address", func(value string) bool {
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an absolute URL with a scheme and a host
+//(e.g. "https://example.com/path")
+func checkURL(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleURL, params, "an absolute URL", func(value string) bool {
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != "" && u.Host != ""
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an URI with a scheme
+//(e.g. "mailto:john@example.com", "urn:isbn:0451450523")
+func checkURI(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleURI, params, "an URI", func(value string) bool {
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an UUID in its canonical textual form
+//The optional param is the required version of the UUID (e.g. `validate:"uuid=4"`)
+func checkUUID(mapKey string, m map[string]string, params ...string) error {
+	if len(params) > 1 || (len(params) == 1 && (len(params[0]) != 1 || params[0] < "1" || params[0] > "8")) {
+		return &FieldError{Key: mapKey, Rule: ruleUUID, Value: m[mapKey], Params: params,
+			Err: fmt.Errorf("rule '%s' expects an optional version param between 1 and 8", ruleUUID)}
+	}
+
+	format := "an UUID"
+	if len(params) == 1 {
+		format = "a version " + params[0] + " UUID"
+	}
+	return checkFormat(mapKey, m, ruleUUID, params, format, func(value string) bool {
+		return uuidPattern.MatchString(value) && (len(params) == 0 || value[14:15] == params[0])
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is a hostname as defined by RFC 1123
+//(e.g. "localhost", "api.example.com")
+func checkHostname(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleHostname, params, "a hostname", isHostname)
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is a fully qualified domain name
+//(e.g. "api.example.com" or "api.example.com.")
+func checkFQDN(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleFQDN, params, "a fully qualified domain name", func(value string) bool {
+		value = strings.TrimSuffix(value, ".")
+		labels := strings.Split(value, ".")
+		return len(labels) > 1 && isHostname(value) && tldPattern.MatchString(labels[len(labels)-1])
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an IPv4 or an IPv6 address
+func checkIP(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleIP, params, "an IP address", func(value string) bool {
+		return net.ParseIP(value) != nil
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an IPv4 address (e.g. "192.168.0.1")
+func checkIPv4(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleIPv4, params, "an IPv4 address", func(value string) bool {
+		return net.ParseIP(value) != nil && !strings.Contains(value, ":")
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an IPv6 address (e.g. "2001:db8::1")
+func checkIPv6(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleIPv6, params, "an IPv6 address", func(value string) bool {
+		return net.ParseIP(value) != nil && strings.Contains(value, ":")
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is an IP network in CIDR notation
+//(e.g. "192.168.0.0/24", "2001:db8::/32")
+func checkCIDR(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleCIDR, params, "a CIDR notation IP network", func(value string) bool {
+		_, _, err := net.ParseCIDR(value)
+		return err == nil
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is a hardware address
+//(e.g. "00:00:5e:00:53:01", "00-00-5e-00-53-01", "0000.5e00.5301")
+func checkMAC(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleMAC, params, "a MAC address", func(value string) bool {
+		_, err := net.ParseMAC(value)
+		return err == nil
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is a non empty standard base64 encoded string
+func checkBase64(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleBase64, params, "a base64 encoded string", func(value string) bool {
+		_, err := base64.StdEncoding.DecodeString(value)
+		return value != "" && err == nil
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is a hexadecimal number with an optional
+//"0x" prefix (e.g. "ff", "0x1A")
+func checkHex(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleHex, params, "a hexadecimal number", hexPattern.MatchString)
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is a semantic version as defined by
+//semver.org (e.g. "1.2.3", "1.0.0-rc.1+build.5")
+func checkSemver(mapKey string, m map[string]string, params ...string) error {
+	return checkFormat(mapKey, m, ruleSemver, params, "a semantic version", semverPattern.MatchString)
+}
+
+//Common implementation of the format rules
+//Checks the map value with "isValid" and returns a FieldError describing the expected "format" if the check fails
+//If the key is not present in the map, the check passes
+func checkFormat(mapKey string, m map[string]string, rule string, params []string, format string,
+	isValid func(value string) bool) error {
+	if mapValue, ok := m[mapKey]; ok {
+		if !isValid(mapValue) {
+			return &FieldError{Key: mapKey, Rule: rule, Value: mapValue, Params: params,
+				Err: fmt.Errorf("map value is not %s", format)}
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+//Checks if the value is a hostname as defined by RFC 1123: dot separated labels of at most 63 letters, digits or
+//hyphens, that do not start or end with a hyphen, with a total length of at most 253 characters
+func isHostname(value string) bool {
+	if value == "" || len(value) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(value, ".") {
+		if !hostnameLabelPattern.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value matches the regular expression provided as param
+//Since the pipe separates the rule params, the params are joined back with pipes to rebuild the expression
+//(e.g. `validate:"regex=^(a|b)$"`); commas inside the expression must be escaped (e.g. "a{1\,3}")
+//The compiled expressions are cached on the Validator; an invalid expression or a pattern that is not registered is a
+//misconfiguration, reported regardless of the map data
+func (v *Validator) checkRegex(mapKey string, m map[string]string, params ...string) error {
+	expr := strings.Join(params, "|")
+	pattern, err := v.compileRegex(expr)
+	if err != nil {
+		return &configError{err: fmt.Errorf("invalid regular expression '%s': %v", expr, err)}
+	}
+	if mapValue, ok := m[mapKey]; ok && !pattern.MatchString(mapValue) {
+		return &FieldError{Key: mapKey, Rule: ruleRegex, Value: mapValue, Params: params,
+			Err: fmt.Errorf("map value does not match the regular expression '%s'", expr)}
+	}
+	return nil
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value matches the regular expression registered
+//with RegisterPattern under the name provided as param (e.g. `validate:"pattern=sku"`)
+func (v *Validator) checkPattern(mapKey string, m map[string]string, params ...string) error {
+	if len(params) != 1 {
+		return &configError{err: fmt.Errorf("rule '%s' expects the pattern name as param", rulePattern)}
+	}
+	pattern, ok := v.lookupPattern(params[0])
+	if !ok {
+		return &configError{err: fmt.Errorf("pattern '%s' is not defined, please use RegisterPattern", params[0])}
+	}
+	if mapValue, ok := m[mapKey]; ok && !pattern.MatchString(mapValue) {
+		return &FieldError{Key: mapKey, Rule: rulePattern, Value: mapValue, Params: params,
+			Err: fmt.Errorf("map value does not match the pattern '%s'", params[0])}
+	}
+	return nil
+}
+
+//Returns the compiled form of a regular expression, compiling it only the first time it is requested
+func (v *Validator) compileRegex(expr string) (*regexp.Regexp, error) {
+	if cached, ok := v.regexCache.Load(expr); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	v.regexCache.Store(expr, pattern)
+
+	return pattern, nil
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is one of the params CASE-SENSITIVE
+//(e.g. `validate:"oneof=active|inactive"`)
+func checkOneOf(mapKey string, m map[string]string, params ...string) error {
+	return checkValues(mapKey, m, ruleOneOf, params, func(a string, b string) bool {
+		return a == b
+	})
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is one of the params CASE-INSENSITIVE
+//(e.g. `validate:"oneofci=active|inactive"` accepts "Active")
+func checkOneOfCI(mapKey string, m map[string]string, params ...string) error {
+	return checkValues(mapKey, m, ruleOneOfCI, params, strings.EqualFold)
+}
+
+//Validates if, for a given key "mapKey" and a given map "m", the value is one of the values registered for the
+//field type via RegisterEnum CASE-SENSITIVE
+func checkEnum(mapKey string, m map[string]string, values ...string) error {
+	return checkValues(mapKey, m, ruleEnum, values, func(a string, b string) bool {
+		return a == b
+	})
+}
+
+//Common implementation of the enumeration rules
+//Compares the map value with each one of the accepted "values" using "equal"
+//If the key is not present in the map, the check passes
+func checkValues(mapKey string, m map[string]string, rule string, values []string,
+	equal func(a string, b string) bool) error {
+	if mapValue, ok := m[mapKey]; ok {
+		for _, value := range values {
+			if equal(mapValue, value) {
+				return nil
+			}
+		}
+		return &FieldError{Key: mapKey, Rule: rule, Value: mapValue, Params: values,
+			Err: fmt.Errorf("map value is not one of (%s)", strings.Join(values, " "))}
+	}
+	return nil
+}
+
+//Validates if the field is present in the map when each one of the referenced keys has the given value
+//The params are pairs of keys and values (e.g. `validate:"required_if=country|RO"` or
+//`validate:"required_if=country|RO|type|company"`)
+func checkRequiredIf(fc fieldContext, params ...string) error {
+	matched, trigger, value, err := matchKeyValues(fc, ruleRequiredIf, params)
+	if err != nil || !matched {
+		return err
+	}
+	return checkCondition(fc, ruleRequiredIf, params, trigger, true,
+		fmt.Sprintf("required when key '%s' is '%s'", trigger, value))
+}
+
+//Validates if the field is present in the map unless each one of the referenced keys has the given value
+//The params are pairs of keys and values (e.g. `validate:"required_unless=type|guest"`)
+func checkRequiredUnless(fc fieldContext, params ...string) error {
+	matched, trigger, value, err := matchKeyValues(fc, ruleRequiredUnless, params)
+	if err != nil || matched {
+		return err
+	}
+	return checkCondition(fc, ruleRequiredUnless, params, trigger, true,
+		fmt.Sprintf("required when key '%s' is not '%s'", trigger, value))
+}
+
+//Validates if the field is present in the map when at least one of the referenced keys is present
+//(e.g. `validate:"required_with=phone|email"`)
+func checkRequiredWith(fc fieldContext, params ...string) error {
+	present, _ := findKeys(fc, params)
+	if len(present) == 0 {
+		return nil
+	}
+	return checkCondition(fc, ruleRequiredWith, params, present[0], true,
+		fmt.Sprintf("required when key '%s' is present", present[0]))
+}
+
+//Validates if the field is present in the map when all the referenced keys are present
+//(e.g. `validate:"required_with_all=street|city"`)
+func checkRequiredWithAll(fc fieldContext, params ...string) error {
+	present, absent := findKeys(fc, params)
+	if len(absent) > 0 || len(present) == 0 {
+		return nil
+	}
+	return checkCondition(fc, ruleRequiredWithAll, params, present[0], true,
+		fmt.Sprintf("required when keys '%s' are present", strings.Join(present, "', '")))
+}
+
+//Validates if the field is present in the map when at least one of the referenced keys is absent
+//(e.g. `validate:"required_without=email"`)
+func checkRequiredWithout(fc fieldContext, params ...string) error {
+	_, absent := findKeys(fc, params)
+	if len(absent) == 0 {
+		return nil
+	}
+	return checkCondition(fc, ruleRequiredWithout, params, absent[0], true,
+		fmt.Sprintf("required when key '%s' is absent", absent[0]))
+}
+
+//Validates if the field is absent from the map when each one of the referenced keys has the given value
+//The params are pairs of keys and values (e.g. `validate:"excluded_if=type|guest"`)
+func checkExcludedIf(fc fieldContext, params ...string) error {
+	matched, trigger, value, err := matchKeyValues(fc, ruleExcludedIf, params)
+	if err != nil || !matched {
+		return err
+	}
+	return checkCondition(fc, ruleExcludedIf, params, trigger, false,
+		fmt.Sprintf("not allowed when key '%s' is '%s'", trigger, value))
+}
+
+//Validates if the field is absent from the map when at least one of the referenced keys is present
+//(e.g. `validate:"excluded_with=company"`)
+func checkExcludedWith(fc fieldContext, params ...string) error {
+	present, _ := findKeys(fc, params)
+	if len(present) == 0 {
+		return nil
+	}
+	return checkCondition(fc, ruleExcludedWith, params, present[0], false,
+		fmt.Sprintf("not allowed when key '%s' is present", present[0]))
+}
+
+//Common implementation of the conditional rules, called when the condition of the rule is met
+//If "required" is true the field must be present in the map, otherwise it must be absent
+//The failure names the key that triggered the rule and "reason" explains the condition
+func checkCondition(fc fieldContext, rule string, params []string, trigger string, required bool,
+	reason string) error {
+	mapValue, ok := fc.m[fc.key]
+	if ok == required {
+		return nil
+	}
+	if required {
+		return &FieldError{Key: fc.key, Rule: rule, Params: params, Trigger: trigger,
+			Err: fmt.Errorf("key '%s' is %s", fc.key, reason)}
+	}
+	return &FieldError{Key: fc.key, Rule: rule, Value: mapValue, Params: params, Trigger: trigger,
+		Err: fmt.Errorf("key '%s' is %s", fc.key, reason)}
+}
+
+//Resolves a key referenced by a rule relative to the key prefix of the struct that contains the field, so that the
+//fields of a sub struct reference the keys of the same sub struct (e.g. "country" -> "billing.country")
+func resolveKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+//Checks if each one of the referenced keys of a conditional rule has the given value
+//The params are pairs of keys and values (see conditionParams); when all of them match, the first key is returned as
+//trigger, otherwise the first key that does not match; the expected value of the trigger is returned too
+//A key without a value is a misconfiguration, reported regardless of the map data
+func matchKeyValues(fc fieldContext, rule string, params []string) (bool, string, string, error) {
+	params = conditionParams(params)
+	if len(params) == 0 || len(params)%2 != 0 {
+		return false, "", "", &configError{err: fmt.Errorf("rule '%s' expects pairs of keys and values, "+
+			"%d params provided", rule, len(params))}
+	}
+	for index := 0; index < len(params); index += 2 {
+		key := resolveKey(fc.prefix, params[index])
+		if value, ok := fc.data[key]; !ok || value != params[index+1] {
+			return false, key, params[index+1], nil
+		}
+	}
+
+	return true, resolveKey(fc.prefix, params[0]), params[1], nil
+}
+
+//Splits the keys referenced by a conditional rule into the ones present in the map and the absent ones
+//The keys are the params of the rule (see conditionParams), resolved relative to the key prefix of the field (see
+//resolveKey)
+func findKeys(fc fieldContext, params []string) ([]string, []string) {
+	params = conditionParams(params)
+	present := make([]string, 0, len(params))
+	absent := make([]string, 0, len(params))
+	for _, param := range params {
+		key := resolveKey(fc.prefix, param)
+		if _, ok := fc.data[key]; ok {
+			present = append(present, key)
+		} else {
+			absent = append(absent, key)
+		}
+	}
+
+	return present, absent
+}
+
+//Returns the params of a conditional rule split on white space, so that the keys and values can be separated by pipes
+//or by spaces (e.g. "required_if=country|RO" and "required_if=country RO", "required_with=phone email")
+//Since the keys and values cannot contain white space, the empty params are dropped as well
+func conditionParams(params []string) []string {
+	split := make([]string, 0, len(params))
+	for _, param := range params {
+		split = append(split, strings.Fields(param)...)
+	}
+
+	return split
+}
+
+//Checks the params of the rules that expect none (e.g. "required", "email")
+func checkNoParams(t reflect.Type, params ...string) error {
+	if len(params) > 0 {
+		return fmt.Errorf("no params expected, %d provided", len(params))
+	}
+	return nil
+}
+
+//Checks the param of the range rules that expect a single bound (e.g. "min", "maxlen"): a number, or a duration for
+//the time.Duration values (see parseBounds)
+func checkBoundParam(t reflect.Type, params ...string) error {
+	if len(params) != 1 {
+		return fmt.Errorf("a single number expected, %d params provided", len(params))
+	}
+	_, err := parseBounds(params, t == durationType)
+	return err
+}
+
+//Checks the params of the "between" rule: two bounds (see checkBoundParam), the lower one first
+func checkBetweenParams(t reflect.Type, params ...string) error {
+	if len(params) != 2 {
+		return fmt.Errorf("two numbers expected, %d params provided", len(params))
+	}
+	bounds, err := parseBounds(params, t == durationType)
+	if err != nil {
+		return err
+	}
+	if bounds[0] > bounds[1] {
+		return fmt.Errorf("the lower bound '%s' is greater than the upper bound '%s'", params[0], params[1])
+	}
+	return nil
+}
+
+//Checks the param of the "uuid" rule: an optional version between 1 and 8
+func checkUUIDParams(t reflect.Type, params ...string) error {
+	if len(params) > 1 || (len(params) == 1 && (len(params[0]) != 1 || params[0] < "1" || params[0] > "8")) {
+		return fmt.Errorf("an optional version between 1 and 8 expected")
+	}
+	return nil
+}
+
+//Checks the params of the "regex" rule: a regular expression that compiles (see checkRegex)
+func (v *Validator) checkRegexParams(t reflect.Type, params ...string) error {
+	if len(params) == 0 {
+		return fmt.Errorf("a regular expression expected")
+	}
+	expr := strings.Join(params, "|")
+	if _, err := v.compileRegex(expr); err != nil {
+		return fmt.Errorf("invalid regular expression '%s': %v", expr, err)
+	}
+	return nil
+}
+
+//Checks the param of the "pattern" rule: the name of a pattern registered with RegisterPattern
+func (v *Validator) checkPatternParams(t reflect.Type, params ...string) error {
+	if len(params) != 1 {
+		return fmt.Errorf("the pattern name expected, %d params provided", len(params))
+	}
+	if _, ok := v.lookupPattern(params[0]); !ok {
+		return fmt.Errorf("pattern '%s' is not defined, please use RegisterPattern", params[0])
+	}
+	return nil
+}
+
+//Checks the params of the enumeration rules ("oneof", "oneofci"): at least one accepted value
+func checkValueParams(t reflect.Type, params ...string) error {
+	if len(params) == 0 {
+		return fmt.Errorf("at least one value expected")
+	}
+	return nil
+}
+
+//Checks the params of the conditional rules that expect pairs of keys and values (e.g. "required_if"), separated by
+//pipes or spaces (see conditionParams)
+func checkKeyValueParams(t reflect.Type, params ...string) error {
+	if split := conditionParams(params); len(split) == 0 || len(split)%2 != 0 {
+		return fmt.Errorf("pairs of keys and values expected, %d params provided", len(split))
+	}
+	return nil
+}
+
+//Checks the params of the conditional rules that expect a list of keys (e.g. "required_with"), separated by pipes or
+//spaces (see conditionParams)
+func checkKeyParams(t reflect.Type, params ...string) error {
+	if len(conditionParams(params)) == 0 {
+		return fmt.Errorf("at least one key expected")
+	}
+	return nil
+}
@@ -1,8 +1,10 @@
 package validator
 
 import (
+	"reflect"
 	"strconv"
 	"testing"
+	"time"
 )
 
 func TestChecks_checkRequired(t *testing.T) {
@@ -241,3 +243,304 @@ func TestChecks_checkBool(t *testing.T) {
 		})
 	}
 }
+
+func TestChecks_checkBounds(t *testing.T) {
+	type MyStruct struct {
+		I  int           `datakey:"i"`
+		F  float64       `datakey:"f"`
+		S  string        `datakey:"s"`
+		P  *int          `datakey:"p"`
+		SL []string      `datakey:"sl"`
+		D  time.Duration `datakey:"d"`
+	}
+	fields := reflect.TypeOf(MyStruct{})
+	field := func(name string) reflect.StructField {
+		f, _ := fields.FieldByName(name)
+		return f
+	}
+
+	var testdata = []struct {
+		rule        func(fc fieldContext, params ...string) error
+		field       string
+		key         string
+		in          map[string]string
+		params      []string
+		noErrorFlag bool
+	}{
+		{checkMin, "I", "i", map[string]string{"i": "10"}, []string{"3"}, true},
+		{checkMin, "I", "i", map[string]string{"i": "2"}, []string{"3"}, false},
+		{checkMin, "I", "i", map[string]string{"i": "-5"}, []string{"-5"}, true},
+		{checkMin, "I", "i", map[string]string{"i": "asdf"}, []string{"3"}, false},
+		{checkMin, "I", "i", map[string]string{}, []string{"3"}, true},
+		{checkMin, "I", "i", map[string]string{"i": "10"}, []string{"x"}, false},
+		{checkMin, "I", "i", map[string]string{"i": "10"}, []string{}, false},
+		{checkMin, "S", "s", map[string]string{"s": "10"}, []string{"3"}, false},
+		{checkMin, "S", "s", map[string]string{"s": "äöü"}, []string{"3"}, true},
+		{checkMin, "P", "p", map[string]string{"p": "10"}, []string{"3"}, true},
+		{checkMax, "F", "f", map[string]string{"f": "2.5"}, []string{"2.5"}, true},
+		{checkMax, "F", "f", map[string]string{"f": "2.51"}, []string{"2.5"}, false},
+		{checkMax, "SL", "sl", map[string]string{"sl": "a,b,c"}, []string{"2"}, false},
+		{checkMax, "SL", "sl", map[string]string{"sl": "a,b"}, []string{"2"}, true},
+		{checkBetween, "I", "i", map[string]string{"i": "5"}, []string{"1", "10"}, true},
+		{checkBetween, "I", "i", map[string]string{"i": "11"}, []string{"1", "10"}, false},
+		{checkBetween, "I", "i", map[string]string{"i": "5"}, []string{"1"}, false},
+		{checkBetween, "S", "s", map[string]string{"s": "qwerty"}, []string{"1", "5"}, false},
+		{checkLen, "S", "s", map[string]string{"s": "qwe"}, []string{"3"}, true},
+		{checkLen, "I", "i", map[string]string{"i": "123"}, []string{"3"}, true},
+		{checkLen, "SL", "sl", map[string]string{"sl": ""}, []string{"0"}, true},
+		{checkMinLen, "S", "s", map[string]string{"s": "qw"}, []string{"3"}, false},
+		{checkMinLen, "SL", "sl", map[string]string{"sl": "a,b,c"}, []string{"3"}, true},
+		{checkMaxLen, "S", "s", map[string]string{"s": "qwer"}, []string{"3"}, false},
+		{checkMaxLen, "S", "s", map[string]string{"s": "qwe"}, []string{"3"}, true},
+		{checkMin, "D", "d", map[string]string{"d": "30s"}, []string{"1"}, true},
+		{checkMin, "D", "d", map[string]string{"d": "1m"}, []string{"1m"}, true},
+		{checkMin, "D", "d", map[string]string{"d": "59s"}, []string{"1m"}, false},
+		{checkMax, "D", "d", map[string]string{"d": "1h"}, []string{"1m30s"}, false},
+		{checkBetween, "D", "d", map[string]string{"d": "90s"}, []string{"1m", "2m"}, true},
+		{checkBetween, "D", "d", map[string]string{"d": "x"}, []string{"1m", "2m"}, false},
+		{checkBetween, "D", "d", map[string]string{"d": "90s"}, []string{"1m", "x"}, false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestCheckBounds_"+strconv.Itoa(i), func(t *testing.T) {
+			err := td.rule(fieldContext{key: td.key, m: td.in, field: field(td.field)}, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestChecks_checkFormats(t *testing.T) {
+	var testdata = []struct {
+		rule        func(mapKey string, m map[string]string, params ...string) error
+		value       string
+		params      []string
+		noErrorFlag bool
+	}{
+		{checkEmail, "john@example.com", nil, true},
+		{checkEmail, "john.doe+tag@sub.example.com", nil, true},
+		{checkEmail, "John <john@example.com>", nil, false},
+		{checkEmail, "john@", nil, false},
+		{checkEmail, "example.com", nil, false},
+		{checkURL, "https://example.com/path?q=1", nil, true},
+		{checkURL, "ftp://127.0.0.1:21", nil, true},
+		{checkURL, "example.com/path", nil, false},
+		{checkURL, "mailto:john@example.com", nil, false},
+		{checkURI, "mailto:john@example.com", nil, true},
+		{checkURI, "urn:isbn:0451450523", nil, true},
+		{checkURI, "/relative/path", nil, false},
+		{checkUUID, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", nil, true},
+		{checkUUID, "6BA7B810-9DAD-11D1-80B4-00C04FD430C8", nil, true},
+		{checkUUID, "6ba7b8109dad11d180b400c04fd430c8", nil, false},
+		{checkUUID, "6ba7b810-9dad-11d1-80b4-00c04fd430cz", nil, false},
+		{checkUUID, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", []string{"1"}, true},
+		{checkUUID, "6ba7b810-9dad-11d1-80b4-00c04fd430c8", []string{"4"}, false},
+		{checkUUID, "f47ac10b-58cc-4372-a567-0e02b2c3d479", []string{"4"}, true},
+		{checkUUID, "f47ac10b-58cc-4372-a567-0e02b2c3d479", []string{"9"}, false},
+		{checkUUID, "f47ac10b-58cc-4372-a567-0e02b2c3d479", []string{"4", "5"}, false},
+		{checkHostname, "localhost", nil, true},
+		{checkHostname, "api.example.com", nil, true},
+		{checkHostname, "-api.example.com", nil, false},
+		{checkHostname, "api_v1.example.com", nil, false},
+		{checkHostname, "api.example.com.", nil, false},
+		{checkFQDN, "api.example.com", nil, true},
+		{checkFQDN, "api.example.com.", nil, true},
+		{checkFQDN, "localhost", nil, false},
+		{checkFQDN, "example.123", nil, false},
+		{checkIP, "192.168.0.1", nil, true},
+		{checkIP, "2001:db8::1", nil, true},
+		{checkIP, "192.168.0.256", nil, false},
+		{checkIPv4, "192.168.0.1", nil, true},
+		{checkIPv4, "2001:db8::1", nil, false},
+		{checkIPv4, "::ffff:192.168.0.1", nil, false},
+		{checkIPv6, "2001:db8::1", nil, true},
+		{checkIPv6, "192.168.0.1", nil, false},
+		{checkCIDR, "192.168.0.0/24", nil, true},
+		{checkCIDR, "2001:db8::/32", nil, true},
+		{checkCIDR, "192.168.0.0", nil, false},
+		{checkMAC, "00:00:5e:00:53:01", nil, true},
+		{checkMAC, "00-00-5e-00-53-01", nil, true},
+		{checkMAC, "00:00:5e:00:53", nil, false},
+		{checkBase64, "cXdlcnR5", nil, true},
+		{checkBase64, "cXdlcnR5eQ==", nil, true},
+		{checkBase64, "cXdlcnR5eQ", nil, false},
+		{checkBase64, "", nil, false},
+		{checkHex, "ff00", nil, true},
+		{checkHex, "0x1A", nil, true},
+		{checkHex, "0x", nil, false},
+		{checkHex, "fg", nil, false},
+		{checkSemver, "1.2.3", nil, true},
+		{checkSemver, "1.0.0-rc.1+build.5", nil, true},
+		{checkSemver, "1.2", nil, false},
+		{checkSemver, "01.2.3", nil, false},
+		{checkSemver, "v1.2.3", nil, false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestCheckFormats_"+strconv.Itoa(i), func(t *testing.T) {
+			err := td.rule("a", map[string]string{"a": td.value}, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if err != nil {
+				if fieldErr, ok := err.(*FieldError); !ok || fieldErr.Key != "a" || fieldErr.Value != td.value ||
+					fieldErr.Rule == "" {
+					t.Error()
+				}
+			}
+		})
+	}
+}
+
+func TestChecks_checkRegex(t *testing.T) {
+	var testdata = []struct {
+		value       string
+		params      []string
+		noErrorFlag bool
+	}{
+		{"123", []string{`^\d+$`}, true},
+		{"12a", []string{`^\d+$`}, false},
+		{"b", []string{"^(a", "b)$"}, true},
+		{"c", []string{"^(a", "b)$"}, false},
+		{"aa", []string{"^a{1,3}$"}, true},
+		{"a", []string{"^(a"}, false},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestCheckRegex_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkRegex("a", map[string]string{"a": td.value}, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+
+	if _, ok := v.regexCache.Load(`^\d+$`); !ok {
+		t.Error()
+	}
+	if _, ok := v.regexCache.Load("^(a"); ok {
+		t.Error()
+	}
+}
+
+func TestChecks_checkPattern(t *testing.T) {
+	var testdata = []struct {
+		value       string
+		params      []string
+		noErrorFlag bool
+	}{
+		{"ABC-1234", []string{"sku"}, true},
+		{"ABC-123", []string{"sku"}, false},
+		{"ABC-1234", []string{"unknown"}, false},
+		{"ABC-1234", []string{}, false},
+	}
+
+	v := New()
+	_ = v.RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$")
+	for i, td := range testdata {
+		t.Run("TestCheckPattern_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkPattern("a", map[string]string{"a": td.value}, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestChecks_checkOneOf(t *testing.T) {
+	var testdata = []struct {
+		rule        func(mapKey string, m map[string]string, params ...string) error
+		value       string
+		params      []string
+		noErrorFlag bool
+	}{
+		{checkOneOf, "a", []string{"a", "b", "c"}, true},
+		{checkOneOf, "c", []string{"a", "b", "c"}, true},
+		{checkOneOf, "A", []string{"a", "b", "c"}, false},
+		{checkOneOf, "d", []string{"a", "b", "c"}, false},
+		{checkOneOf, "", []string{}, false},
+		{checkOneOfCI, "A", []string{"a", "b", "c"}, true},
+		{checkOneOfCI, "Active", []string{"active", "inactive"}, true},
+		{checkOneOfCI, "d", []string{"a", "b", "c"}, false},
+		{checkEnum, "a", []string{"a", "b", "c"}, true},
+		{checkEnum, "A", []string{"a", "b", "c"}, false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestCheckOneOf_"+strconv.Itoa(i), func(t *testing.T) {
+			err := td.rule("a", map[string]string{"a": td.value}, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestChecks_checkConditions(t *testing.T) {
+	var testdata = []struct {
+		rule        func(fc fieldContext, params ...string) error
+		prefix      string
+		in          map[string]string
+		params      []string
+		trigger     string
+		noErrorFlag bool
+	}{
+		{checkRequiredIf, "", map[string]string{"c": "RO"}, []string{"c", "RO"}, "c", false},
+		{checkRequiredIf, "", map[string]string{"c": "RO", "a": "1"}, []string{"c", "RO"}, "", true},
+		{checkRequiredIf, "", map[string]string{"c": "MD"}, []string{"c", "RO"}, "", true},
+		{checkRequiredIf, "", map[string]string{"c": "RO", "t": "x"}, []string{"c", "RO", "t", "y"}, "", true},
+		{checkRequiredIf, "", map[string]string{"c": "RO"}, []string{"c"}, "", false},
+		{checkRequiredIf, "b", map[string]string{"b.c": "RO"}, []string{"c", "RO"}, "b.c", false},
+		{checkRequiredIf, "b", map[string]string{"c": "RO"}, []string{"c", "RO"}, "", true},
+		{checkRequiredUnless, "", map[string]string{"t": "guest"}, []string{"t", "guest"}, "", true},
+		{checkRequiredUnless, "", map[string]string{"t": "user"}, []string{"t", "guest"}, "t", false},
+		{checkRequiredUnless, "", map[string]string{}, []string{"t", "guest"}, "t", false},
+		{checkRequiredWith, "", map[string]string{"e": "x"}, []string{"p", "e"}, "e", false},
+		{checkRequiredWith, "", map[string]string{}, []string{"p", "e"}, "", true},
+		{checkRequiredWithAll, "", map[string]string{"p": "x"}, []string{"p", "e"}, "", true},
+		{checkRequiredWithAll, "", map[string]string{"p": "x", "e": "y"}, []string{"p", "e"}, "p", false},
+		{checkRequiredWithout, "", map[string]string{"p": "x"}, []string{"p", "e"}, "e", false},
+		{checkRequiredWithout, "", map[string]string{"p": "x", "e": "y"}, []string{"p", "e"}, "", true},
+		{checkExcludedIf, "", map[string]string{"t": "guest", "a": "1"}, []string{"t", "guest"}, "t", false},
+		{checkExcludedIf, "", map[string]string{"t": "guest"}, []string{"t", "guest"}, "", true},
+		{checkExcludedWith, "", map[string]string{"c": "x", "a": "1"}, []string{"c"}, "c", false},
+		{checkExcludedWith, "", map[string]string{"a": "1"}, []string{"c"}, "", true},
+		{checkRequiredIf, "", map[string]string{"c": "RO"}, []string{"c RO"}, "c", false},
+		{checkRequiredIf, "", map[string]string{"c": "MD"}, []string{"c RO"}, "", true},
+		{checkRequiredIf, "", map[string]string{"c": "RO", "t": "x"}, []string{"c RO  t x"}, "c", false},
+		{checkRequiredIf, "", map[string]string{"c": "RO"}, []string{"c RO t"}, "", false},
+		{checkRequiredWith, "", map[string]string{"f": "x"}, []string{"e f"}, "f", false},
+		{checkRequiredWith, "", map[string]string{"e f": "x"}, []string{"e f"}, "", true},
+		{checkRequiredWithout, "", map[string]string{"e": "x"}, []string{"e f"}, "f", false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestCheckConditions_"+strconv.Itoa(i), func(t *testing.T) {
+			key := "a"
+			if td.prefix != "" {
+				key = td.prefix + ".a"
+			}
+			err := td.rule(fieldContext{key: key, m: td.in, data: td.in, prefix: td.prefix}, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if fieldErr, ok := err.(*FieldError); ok && fieldErr.Trigger != td.trigger {
+				t.Error()
+			}
+		})
+	}
+}
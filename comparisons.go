@@ -0,0 +1,307 @@
+//This file contains the value rules, applied on the converted values of the fields after the initialization, like
+//the cross-field comparison rules ("eqfield", "nefield", "gtfield", "ltfield"):
+//
+//		type Period struct {
+//			Start time.Time `datakey:"start" validate:"required,time"`
+//			End   time.Time `datakey:"end" validate:"required,time,gtfield=start"`
+//		}
+//
+//The other field is referenced by its "datakey" tag; inside a sub struct with a key prefix, the key is resolved
+//relative to the same prefix (e.g. "start" -> "period.start")
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+//Returns the converted value of a field of the validated struct, referenced by its "datakey" tag
+//The key is resolved relative to the key prefix of the struct that contains the validated field
+//Returns false if no field is linked to the key or if the key is not present in the map data (after the defaults are
+//applied); for the structs validated by ValidateStruct, every field linked to a key is found
+type FieldLookup func(key string) (reflect.Value, bool)
+
+//Validates if the value of the field is equal to the value of the referenced field
+//(e.g. `validate:"eqfield=password"`)
+func checkEqField(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkFieldComparison(value, lookup, ruleEqField, params, "equal to", func(result int) bool {
+		return result == 0
+	})
+}
+
+//Validates if the value of the field is different from the value of the referenced field
+//(e.g. `validate:"nefield=old_password"`)
+func checkNeField(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkFieldComparison(value, lookup, ruleNeField, params, "different from", func(result int) bool {
+		return result != 0
+	})
+}
+
+//Validates if the value of the field is greater than the value of the referenced field; times are compared
+//chronologically, numbers by value and strings lexicographically (e.g. `validate:"gtfield=start"`)
+func checkGtField(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkFieldComparison(value, lookup, ruleGtField, params, "greater than", func(result int) bool {
+		return result > 0
+	})
+}
+
+//Validates if the value of the field is less than the value of the referenced field; times are compared
+//chronologically, numbers by value and strings lexicographically (e.g. `validate:"ltfield=end"`)
+func checkLtField(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkFieldComparison(value, lookup, ruleLtField, params, "less than", func(result int) bool {
+		return result < 0
+	})
+}
+
+//Common implementation of the cross-field comparison rules
+//Compares the value of the field with the value of the field referenced by the only param and checks the result of
+//the comparison (-1, 0 or 1) with "accept"
+//The rule is skipped if the referenced key was not provided; the keys that are not linked to any field are reported
+//by Compile, while a wrong number of params and the fields whose types cannot be compared are misconfigurations,
+//returned as plain errors (see configError)
+func checkFieldComparison(value reflect.Value, lookup FieldLookup, rule string, params []string, relation string,
+	accept func(result int) bool) error {
+	if len(params) != 1 {
+		return &configError{err: fmt.Errorf("rule '%s' expects the key of a field as the only param, "+
+			"%d params provided", rule, len(params))}
+	}
+	other, ok := lookup(params[0])
+	if !ok {
+		return nil
+	}
+
+	result, err := compareValues(value, other, rule == ruleEqField || rule == ruleNeField)
+	if err != nil {
+		return &configError{err: err}
+	}
+	if !accept(result) {
+		return &FieldError{Rule: rule, Params: params,
+			Err: fmt.Errorf("value is not %s the value of key '%s'", relation, params[0])}
+	}
+	return nil
+}
+
+//Checks the param of the cross-field comparison rules: the key of the referenced field
+//Compile checks that the key is linked to a field as well
+func checkFieldParam(t reflect.Type, params ...string) error {
+	if len(params) != 1 || params[0] == "" {
+		return fmt.Errorf("the key of a field expected as the only param, %d params provided", len(params))
+	}
+	return nil
+}
+
+//Checks if a rule is one of the builtin cross-field comparison rules
+func isComparisonRule(name string) bool {
+	switch name {
+	case ruleEqField, ruleNeField, ruleGtField, ruleLtField:
+		return true
+	default:
+		return false
+	}
+}
+
+//Compares two converted values and returns -1, 0 or 1 if "a" is less than, equal to or greater than "b"
+//Pointers are compared by the values they point to (nil pointers are less than any value), times chronologically,
+//numbers by value (regardless of their size) and strings lexicographically
+//If "equality" is true, values that have no order (e.g. bool values, structs) are compared for equality, returning
+//0 or 1
+func compareValues(a reflect.Value, b reflect.Value, equality bool) (int, error) {
+	for a.Kind() == reflect.Ptr || b.Kind() == reflect.Ptr {
+		switch {
+		case a.Kind() == reflect.Ptr && a.IsNil() && b.Kind() == reflect.Ptr && b.IsNil():
+			return 0, nil
+		case a.Kind() == reflect.Ptr && a.IsNil():
+			return -1, nil
+		case b.Kind() == reflect.Ptr && b.IsNil():
+			return 1, nil
+		}
+		a, b = reflect.Indirect(a), reflect.Indirect(b)
+	}
+
+	timeType := reflect.TypeOf(time.Time{})
+	switch {
+	case a.Type() == timeType && b.Type() == timeType:
+		ta, tb := a.Interface().(time.Time), b.Interface().(time.Time)
+		return compareOrdered(ta.Before(tb), ta.After(tb)), nil
+	case isSigned(a.Kind()) && isSigned(b.Kind()):
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int()), nil
+	case isUnsigned(a.Kind()) && isUnsigned(b.Kind()):
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint()), nil
+	case isNumber(a.Kind()) && isNumber(b.Kind()):
+		fa, fb := toFloat(a), toFloat(b)
+		return compareOrdered(fa < fb, fa > fb), nil
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return compareOrdered(a.String() < b.String(), a.String() > b.String()), nil
+	case equality && a.Type() == b.Type():
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			return 0, nil
+		}
+		return 1, nil
+	default:
+		return 0, fmt.Errorf("values of type '%s' and '%s' cannot be compared", a.Type(), b.Type())
+	}
+}
+
+//Turns the result of an ordered comparison into -1, 0 or 1
+func compareOrdered(less bool, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+//Checks if a kind is a signed integer kind
+func isSigned(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	default:
+		return false
+	}
+}
+
+//Checks if a kind is an unsigned integer kind
+func isUnsigned(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	default:
+		return false
+	}
+}
+
+//Checks if a kind is a numeric kind
+func isNumber(kind reflect.Kind) bool {
+	return isSigned(kind) || isUnsigned(kind) || kind == reflect.Float32 || kind == reflect.Float64
+}
+
+//Returns a numeric value as float64
+func toFloat(value reflect.Value) float64 {
+	switch {
+	case isSigned(value.Kind()):
+		return float64(value.Int())
+	case isUnsigned(value.Kind()):
+		return float64(value.Uint())
+	default:
+		return value.Float()
+	}
+}
+
+//Applies the value rules defined on the struct's tags on the converted values of the initialized struct "t"
+//The rules of a field are applied only if the key of the field is present in the map, and they can only look up the
+//fields whose keys are present too
+//When the Validator collects all the errors, the failures are returned together as ValidationErrors
+func (v *Validator) checkValueRules(m map[string]string, t reflect.Value) error {
+	values := make(map[string]reflect.Value)
+	_ = v.walkValues(m, t, structPosition{parents: []reflect.Type{t.Type()}},
+		func(fp fieldPlan, value reflect.Value, position structPosition) error {
+			mapKey := position.key(fp.field)
+			if _, isPresent := m[mapKey]; isPresent && mapKey != "" && fp.field.PkgPath == "" {
+				values[mapKey] = value
+			}
+			return nil
+		})
+
+	failures := ValidationErrors{}
+	err := v.walkValues(m, t, structPosition{parents: []reflect.Type{t.Type()}},
+		func(fp fieldPlan, value reflect.Value, position structPosition) error {
+			mapKey := position.key(fp.field)
+			if _, isPresent := m[mapKey]; !fp.hasRules || !isPresent || mapKey == "" {
+				return nil
+			}
+			if fp.rulesErr != nil {
+				return fp.rulesErr
+			}
+
+			lookup := func(key string) (reflect.Value, bool) {
+				value, ok := values[resolveKey(position.prefix, key)]
+				return value, ok
+			}
+			for _, rule := range fp.rules {
+				if rule.valueRule == nil {
+					continue
+				}
+				err := rule.valueRule(value, lookup, rule.params...)
+				if err != nil {
+					path := fieldPath(position.path, fp.field.Name)
+					if err := ruleConfigError(err, rule.name, path); err != nil {
+						return err
+					}
+					fieldErr := toFieldError(err, path, mapKey, rule.name, m[mapKey], rule.params)
+					if err := v.reportFailure(fieldErr, &failures); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+//Calls "fn" for each field of the initialized struct found at "position" and of its sub structs, together with the
+//plan of the field, the field value and the position of the struct that contains the field
+//Nil pointers to sub structs are skipped and the elements of the slices of sub structs are visited only if they are
+//found in the map; if "m" is nil, all the elements are visited (e.g. for the structs validated by ValidateStruct)
+//The unexported sub structs are skipped as well, unless they are embedded, since the values of their fields cannot be
+//read
+//The walk stops at the first error returned by "fn"
+func (v *Validator) walkValues(m map[string]string, t reflect.Value, position structPosition,
+	fn func(fp fieldPlan, value reflect.Value, position structPosition) error) error {
+	for _, fp := range v.plan(t.Type()).fields {
+		field := fp.field
+		value := t.Field(fp.index)
+		isReadable := field.PkgPath == "" || field.Anonymous
+		isNil := field.Type.Kind() == reflect.Ptr && value.IsNil()
+		if nestedType, ok := position.nested(field); ok && isReadable && !isNil {
+			err := v.walkValues(m, reflect.Indirect(value), position.child(field, nestedType), fn)
+			if err != nil {
+				return err
+			}
+		}
+
+		mapKey := position.key(field)
+		if fp.isStructList && mapKey != "" {
+			elements := findElements(m, mapKey)
+			if m == nil {
+				elements = make([]mapElement, 0, value.Len())
+				for index := 0; index < value.Len(); index++ {
+					elements = append(elements, mapElement{index: index, prefix: fmt.Sprintf("%s[%d]", mapKey, index)})
+				}
+			}
+			for _, element := range elements {
+				if element.index >= value.Len() {
+					break
+				}
+				elementValue := reflect.Indirect(value.Index(element.index))
+				if !elementValue.IsValid() {
+					continue
+				}
+				elementPosition := position.with(field, mapKey).element(element, fp.elementType)
+				err := v.walkValues(m, elementValue, elementPosition, fn)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err := fn(fp, value, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
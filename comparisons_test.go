@@ -0,0 +1,132 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestComparisons_compareValues(t *testing.T) {
+	start := time.Date(2019, 8, 21, 9, 0, 0, 0, time.UTC)
+	one := 1
+	testdata := []struct {
+		a           interface{}
+		b           interface{}
+		equality    bool
+		out         int
+		noErrorFlag bool
+	}{
+		{start, start.Add(time.Hour), false, -1, true},
+		{start.Add(time.Hour), start, false, 1, true},
+		{int8(3), int64(3), false, 0, true},
+		{uint(3), uint16(2), false, 1, true},
+		{1.5, 2, false, -1, true},
+		{uint(3), -1, false, 1, true},
+		{"abc", "abd", false, -1, true},
+		{&one, 1, false, 0, true},
+		{(*int)(nil), 1, false, -1, true},
+		{(*int)(nil), (*int)(nil), false, 0, true},
+		{true, true, true, 0, true},
+		{true, false, true, 1, true},
+		{true, false, false, 0, false},
+		{"1", 1, true, 0, false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestCompareValues_"+strconv.Itoa(i), func(t *testing.T) {
+			result, err := compareValues(reflect.ValueOf(td.a), reflect.ValueOf(td.b), td.equality)
+			if td.noErrorFlag && (err != nil || result != td.out) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestComparisons_checkFieldComparison(t *testing.T) {
+	fields := map[string]reflect.Value{"a": reflect.ValueOf(5), "s": reflect.ValueOf("x")}
+	lookup := func(key string) (reflect.Value, bool) {
+		value, ok := fields[key]
+		return value, ok
+	}
+	testdata := []struct {
+		rule        func(value reflect.Value, lookup FieldLookup, params ...string) error
+		in          interface{}
+		params      []string
+		noErrorFlag bool
+	}{
+		{checkEqField, 5, []string{"a"}, true},
+		{checkEqField, 6, []string{"a"}, false},
+		{checkNeField, 6, []string{"a"}, true},
+		{checkNeField, "x", []string{"s"}, false},
+		{checkGtField, 6, []string{"a"}, true},
+		{checkGtField, 5, []string{"a"}, false},
+		{checkLtField, 4, []string{"a"}, true},
+		{checkLtField, 5, []string{"a"}, false},
+		{checkLtField, 5, []string{"b"}, true},
+		{checkLtField, 5, []string{}, false},
+		{checkLtField, "y", []string{"a"}, false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestCheckFieldComparison_"+strconv.Itoa(i), func(t *testing.T) {
+			err := td.rule(reflect.ValueOf(td.in), lookup, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestComparisons_checkValueRules(t *testing.T) {
+	type Period struct {
+		Start time.Time `datakey:"start" validate:"time"`
+		End   time.Time `datakey:"end" validate:"time,gtfield=start"`
+		Min   int       `datakey:"min" default:"5"`
+		Max   int       `datakey:"max" validate:"gtfield=min"`
+	}
+	testdata := []struct {
+		in          map[string]string
+		noErrorFlag bool
+	}{
+		{map[string]string{"end": "2019-08-21T09:00:00Z"}, true},
+		{map[string]string{"start": "2019-08-21T09:00:00Z", "end": "2019-08-21T10:00:00Z"}, true},
+		{map[string]string{"start": "2019-08-21T09:00:00Z", "end": "2019-08-21T08:00:00Z"}, false},
+		{map[string]string{"start": "0001-01-01T00:00:00Z", "end": "0001-01-01T00:00:00Z"}, false},
+		{map[string]string{"max": "6"}, true},
+		{map[string]string{"max": "5"}, false},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestComparisons_checkValueRules_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.ValidateAndInit(td.in, &Period{})
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestComparisons_checkValueRules2(t *testing.T) {
+	type MyStruct struct {
+		Start time.Time `datakey:"start" validate:"time"`
+		End   int       `datakey:"end" validate:"gtfield=start"`
+	}
+
+	v := New()
+	v.SetCollectAllErrors(true)
+	//The fields whose types cannot be compared are reported as a plain error, not as a failure
+	err := v.ValidateAndInit(map[string]string{"start": "2019-08-21T09:00:00Z", "end": "1"}, &MyStruct{})
+	var failures ValidationErrors
+	if err == nil || errors.As(err, &failures) {
+		t.Error(err)
+	}
+}
@@ -1,44 +1,160 @@
 //This file is used to define al the builtin type converters (from string to interface{}) of the validators
-//The current converters are: convertToInt, convertToTime, convertToString
+//The current converters are: convertToInt, convertToFloat, convertToTime, convertToString, convertToBool
+//Besides them, the types implementing encoding.TextUnmarshaler, flag.Value or sql.Scanner are converted via the
+//converters built by interfaceConverter
+//The converters report their failures as FieldError values
 
 package validator
 
 import (
+	"database/sql"
+	"encoding"
+	"flag"
 	"fmt"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
-//Converts a string numeric value to and int type value
-//The optional parameter is used to provide the type of int return value: int, uint, int64
+//The interfaces that a type can implement in order to be converted from a string without a registered converter
+//They are checked in this order on the pointer type of the converted type (e.g. "*net.IP")
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	flagValueType       = reflect.TypeOf((*flag.Value)(nil)).Elem()
+	sqlScannerType      = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+//Converts a string numeric value to an integer type value
+//The optional parameter is used to provide the type of the integer return value: int, int8, int16, int32, int64,
+//uint, uint8, uint16, uint32, uint64, uintptr; any other value results in an int
+//Values that do not fit the requested type and negative values for the unsigned types are reported as failures
 func convertToInt(value string, params ...string) (interface{}, error) {
-	intValue, err := strconv.Atoi(value)
-	if err != nil {
-		return nil, fmt.Errorf("error converting '%s' to int", value)
+	intType := convertInt
+	if len(params) > 0 {
+		intType = params[0]
 	}
 
-	switch params[0] {
-	case "int":
-		return intValue, nil
-	case "uint":
-		return uint(intValue), nil
-	case "int64":
-		return int64(intValue), nil
+	var result interface{}
+	var err error
+	switch intType {
+	case convertInt8:
+		intValue, parseErr := parseSigned(value, intType, 8)
+		result, err = int8(intValue), parseErr
+	case convertInt16:
+		intValue, parseErr := parseSigned(value, intType, 16)
+		result, err = int16(intValue), parseErr
+	case convertInt32:
+		intValue, parseErr := parseSigned(value, intType, 32)
+		result, err = int32(intValue), parseErr
+	case convertInt64:
+		intValue, parseErr := parseSigned(value, intType, 64)
+		result, err = intValue, parseErr
+	case convertUint:
+		uintValue, parseErr := parseUnsigned(value, intType, strconv.IntSize)
+		result, err = uint(uintValue), parseErr
+	case convertUint8:
+		uintValue, parseErr := parseUnsigned(value, intType, 8)
+		result, err = uint8(uintValue), parseErr
+	case convertUint16:
+		uintValue, parseErr := parseUnsigned(value, intType, 16)
+		result, err = uint16(uintValue), parseErr
+	case convertUint32:
+		uintValue, parseErr := parseUnsigned(value, intType, 32)
+		result, err = uint32(uintValue), parseErr
+	case convertUint64:
+		uintValue, parseErr := parseUnsigned(value, intType, 64)
+		result, err = uintValue, parseErr
+	case convertUintptr:
+		uintValue, parseErr := parseUnsigned(value, intType, strconv.IntSize)
+		result, err = uintptr(uintValue), parseErr
 	default:
-		return intValue, nil
+		intValue, parseErr := parseSigned(value, convertInt, strconv.IntSize)
+		result, err = int(intValue), parseErr
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+//Converts a string numeric value to a float type value
+//The optional parameter is used to provide the type of the float return value: float32 or float64 (default)
+//Values that do not fit the requested type are reported as failures
+func convertToFloat(value string, params ...string) (interface{}, error) {
+	floatType := convertFloat64
+	bitSize := 64
+	if len(params) > 0 && params[0] == convertFloat32 {
+		floatType = convertFloat32
+		bitSize = 32
+	}
+
+	floatValue, err := strconv.ParseFloat(value, bitSize)
+	if err != nil {
+		return nil, &FieldError{Value: value, Err: numberError(value, floatType, err)}
+	}
+	if bitSize == 32 {
+		return float32(floatValue), nil
+	}
+
+	return floatValue, nil
+}
+
+//Parses a signed integer of the given bit size
+func parseSigned(value string, intType string, bitSize int) (int64, error) {
+	intValue, err := strconv.ParseInt(value, 10, bitSize)
+	if err != nil {
+		return 0, &FieldError{Value: value, Err: numberError(value, intType, err)}
+	}
+
+	return intValue, nil
+}
+
+//Parses an unsigned integer of the given bit size
+//Negative values are reported as failures instead of being wrapped around
+func parseUnsigned(value string, intType string, bitSize int) (uint64, error) {
+	if strings.HasPrefix(value, "-") {
+		return 0, &FieldError{Value: value,
+			Err: fmt.Errorf("error converting '%s' to %s: negative value for an unsigned type", value, intType)}
+	}
+	uintValue, err := strconv.ParseUint(value, 10, bitSize)
+	if err != nil {
+		return 0, &FieldError{Value: value, Err: numberError(value, intType, err)}
+	}
+
+	return uintValue, nil
+}
+
+//Describes why the parsing of a number failed: the value is either not a number or it does not fit the type
+func numberError(value string, numberType string, err error) error {
+	if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+		return fmt.Errorf("error converting '%s' to %s: value out of range", value, numberType)
+	}
+
+	return fmt.Errorf("error converting '%s' to %s", value, numberType)
 }
 
 //Converts a string time value to a time.Time value
 func convertToTime(value string, params ...string) (interface{}, error) {
 	time_, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing time string")
+		return nil, &FieldError{Value: value, Err: fmt.Errorf("error parsing time string: %v", err)}
 	}
 
 	return time_, nil
 }
 
+//Converts a string duration value (e.g. "30s", "1h30m") to a time.Duration value
+func convertToDuration(value string, params ...string) (interface{}, error) {
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return nil, &FieldError{Value: value, Err: fmt.Errorf("error parsing duration string: %v", err)}
+	}
+
+	return duration, nil
+}
+
 //Converts a string to a string value
 //Basically it just returns the value
 //Defined in order to have consistency and to work well with the overall converter mechanism
@@ -50,8 +166,43 @@ func convertToString(value string, params ...string) (interface{}, error) {
 func convertToBool(value string, params ...string) (interface{}, error) {
 	b, err := strconv.ParseBool(value)
 	if err != nil {
-		return nil, fmt.Errorf("error checking bool string")
+		return nil, &FieldError{Value: value, Err: fmt.Errorf("error checking bool string")}
 	}
 
 	return b, nil
-}
\ No newline at end of file
+}
+
+//Builds a converter for a type whose pointer type implements encoding.TextUnmarshaler, flag.Value or sql.Scanner
+//(e.g. net.IP, big.Int, sql.NullString)
+//The converter allocates a new value of the type and lets it parse the string value through the interface method
+//If the type implements none of the interfaces, it returns false
+func interfaceConverter(t reflect.Type) (func(value string, params ...string) (interface{}, error), bool) {
+	ptrType := reflect.PtrTo(t)
+	var parse func(target interface{}, value string) error
+	switch {
+	case ptrType.Implements(textUnmarshalerType):
+		parse = func(target interface{}, value string) error {
+			return target.(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+		}
+	case ptrType.Implements(flagValueType):
+		parse = func(target interface{}, value string) error {
+			return target.(flag.Value).Set(value)
+		}
+	case ptrType.Implements(sqlScannerType):
+		parse = func(target interface{}, value string) error {
+			return target.(sql.Scanner).Scan(value)
+		}
+	default:
+		return nil, false
+	}
+
+	return func(value string, params ...string) (interface{}, error) {
+		target := reflect.New(t)
+		err := parse(target.Interface(), value)
+		if err != nil {
+			return nil, &FieldError{Value: value, Err: fmt.Errorf("error converting '%s' to %s: %v", value, t, err)}
+		}
+
+		return target.Elem().Interface(), nil
+	}, true
+}
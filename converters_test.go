@@ -1,7 +1,13 @@
 package validator
 
 import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"net"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -81,7 +87,7 @@ func TestConverters_convertToInt(t *testing.T) {
 			"-123",
 			-123,
 			"uint",
-			true,
+			false,
 		},
 		{
 			"qewrq",
@@ -151,6 +157,31 @@ func TestConverters_convertToTime(t *testing.T) {
 	}
 }
 
+func TestConverters_convertToDuration(t *testing.T) {
+	testdata := []struct {
+		in          string
+		out         time.Duration
+		noErrorFlag bool
+	}{
+		{"30s", 30 * time.Second, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"-5ms", -5 * time.Millisecond, true},
+		{"30", 0, false},
+		{"", 0, false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestConvertToDuration_"+strconv.Itoa(i), func(t *testing.T) {
+			result, err := convertToDuration(td.in)
+			if td.noErrorFlag && (err != nil || result != td.out) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
 func TestConverters_convertToBool(t *testing.T) {
 	testdata := []struct {
 		in          string
@@ -193,4 +224,130 @@ func TestConverters_convertToBool(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestConverters_convertToInt2(t *testing.T) {
+	testdata := []struct {
+		in          string
+		out         interface{}
+		case_       string
+		noErrorFlag bool
+	}{
+		{"127", int8(127), "int8", true},
+		{"-128", int8(-128), "int8", true},
+		{"128", nil, "int8", false},
+		{"32767", int16(32767), "int16", true},
+		{"32768", nil, "int16", false},
+		{"-2147483648", int32(-2147483648), "int32", true},
+		{"2147483648", nil, "int32", false},
+		{"9223372036854775807", int64(9223372036854775807), "int64", true},
+		{"9223372036854775808", nil, "int64", false},
+		{"255", uint8(255), "uint8", true},
+		{"256", nil, "uint8", false},
+		{"-1", nil, "uint8", false},
+		{"65535", uint16(65535), "uint16", true},
+		{"4294967295", uint32(4294967295), "uint32", true},
+		{"4294967296", nil, "uint32", false},
+		{"18446744073709551615", uint64(18446744073709551615), "uint64", true},
+		{"-0", nil, "uint64", false},
+		{"42", uintptr(42), "uintptr", true},
+		{"42", uint(42), "uint", true},
+		{"-42", -42, "int", true},
+		{"1.5", nil, "int", false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestConvertToInt2_"+strconv.Itoa(i), func(t *testing.T) {
+			result, err := convertToInt(td.in, td.case_)
+			if td.noErrorFlag && (err != nil || result != td.out) {
+				t.Error()
+			} else if !td.noErrorFlag && (err == nil || result != nil) {
+				t.Error()
+			}
+			if err != nil {
+				if fieldErr, ok := err.(*FieldError); !ok || fieldErr.Value != td.in {
+					t.Error()
+				}
+			}
+		})
+	}
+}
+
+func TestConverters_convertToFloat(t *testing.T) {
+	testdata := []struct {
+		in          string
+		out         interface{}
+		case_       string
+		noErrorFlag bool
+	}{
+		{"1.5", float32(1.5), "float32", true},
+		{"1.5", 1.5, "float64", true},
+		{"-2", -2.0, "no case; do default", true},
+		{"1e39", nil, "float32", false},
+		{"1e39", 1e39, "float64", true},
+		{"1e309", nil, "float64", false},
+		{"abc", nil, "float64", false},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestConvertToFloat_"+strconv.Itoa(i), func(t *testing.T) {
+			result, err := convertToFloat(td.in, td.case_)
+			if td.noErrorFlag && (err != nil || result != td.out) {
+				t.Error()
+			} else if !td.noErrorFlag && (err == nil || result != nil) {
+				t.Error()
+			}
+		})
+	}
+}
+
+type csvFlag []string
+
+func (c *csvFlag) String() string {
+	return strings.Join(*c, ",")
+}
+
+func (c *csvFlag) Set(value string) error {
+	if value == "" {
+		return fmt.Errorf("empty value")
+	}
+	*c = strings.Split(value, ",")
+	return nil
+}
+
+func TestConverters_interfaceConverter(t *testing.T) {
+	bigInt, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	testdata := []struct {
+		in          string
+		typ         reflect.Type
+		out         interface{}
+		noErrorFlag bool
+	}{
+		{"192.168.0.1", reflect.TypeOf(net.IP{}), net.ParseIP("192.168.0.1"), true},
+		{"192.168.0.300", reflect.TypeOf(net.IP{}), nil, false},
+		{"123456789012345678901234567890", reflect.TypeOf(big.Int{}), *bigInt, true},
+		{"12a", reflect.TypeOf(big.Int{}), nil, false},
+		{"a,b", reflect.TypeOf(csvFlag{}), csvFlag{"a", "b"}, true},
+		{"", reflect.TypeOf(csvFlag{}), nil, false},
+		{"qwerty", reflect.TypeOf(sql.NullString{}), sql.NullString{String: "qwerty", Valid: true}, true},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestInterfaceConverter_"+strconv.Itoa(i), func(t *testing.T) {
+			converter, ok := interfaceConverter(td.typ)
+			if !ok {
+				t.Fatal()
+			}
+			result, err := converter(td.in)
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(result, td.out)) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+
+	if _, ok := interfaceConverter(reflect.TypeOf(0)); ok {
+		t.Error()
+	}
+}
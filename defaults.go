@@ -0,0 +1,101 @@
+//This file contains the support for default values, defined by the "default" tag of the struct fields
+//
+//The default value of a field is used when the map key linked to the field is absent: it is added to the map before
+//the validation step, so the rules (e.g. "required") and the converters see it as if it was provided
+//The default values are checked once per struct type, by converting them with the converter of their field, so an
+//invalid default value is reported before any data is processed
+
+package validator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+//Returns the map data completed with the default values of the fields whose keys are absent
+//The default values of the optional sub structs (pointers to sub structs) are added only if at least one of the sub
+//struct keys is present, and the ones of the slices of sub structs only for the elements found in the map
+//The provided map is never modified: a copy is returned if at least one default value is added
+func (v *Validator) applyDefaults(m map[string]string, t reflect.Type) (map[string]string, error) {
+	err := v.checkDefaults(t)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]string)
+	v.collectDefaults(m, t, structPosition{parents: []reflect.Type{t}}, defaults)
+	if len(defaults) == 0 {
+		return m, nil
+	}
+
+	result := copyMap(m)
+	for key, value := range defaults {
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+//Stores inside "defaults" the default values of the fields of the struct found at "position" whose keys are absent
+func (v *Validator) collectDefaults(m map[string]string, t reflect.Type, position structPosition,
+	defaults map[string]string) {
+	_ = v.walkFields(m, t, position, func(fp fieldPlan, position structPosition) error {
+		mapKey := position.key(fp.field)
+		value, ok := fp.field.Tag.Lookup(tagDefault)
+		if _, isPresent := m[mapKey]; ok && !isPresent && mapKey != "" {
+			defaults[mapKey] = value
+		}
+		return nil
+	})
+}
+
+//Checks the default values of a struct type, the first time the type is processed; later calls return the result of
+//the first check
+func (v *Validator) checkDefaults(t reflect.Type) error {
+	if result, ok := v.defaultErrors.Load(t); ok {
+		err, _ := result.(error)
+		return err
+	}
+
+	generation := v.registryGeneration()
+	err := v.checkTypeDefaults(t, structPosition{parents: []reflect.Type{t}})
+	v.storeCached(&v.defaultErrors, t, err, generation)
+
+	return err
+}
+
+//Checks that the default values of the struct found at "position" (and of its sub structs) can be converted to the
+//types of their fields
+//Default values can only be defined on the fields linked to a map key whose value is converted (e.g. not on sub
+//structs, slices of sub structs or map fields)
+func (v *Validator) checkTypeDefaults(t reflect.Type, position structPosition) error {
+	return v.walkTypes(t, position, v.checkFieldDefault)
+}
+
+//Checks that the default value of a field, if any, can be converted to the type of the field
+func (v *Validator) checkFieldDefault(fp fieldPlan, position structPosition) error {
+	field := fp.field
+	path := fieldPath(position.path, field.Name)
+	value, ok := field.Tag.Lookup(tagDefault)
+	if !ok {
+		return nil
+	}
+	_, isNested := position.nested(field)
+	_, _, hasConverter := v.resolveConverter(fieldType(field))
+	switch {
+	case field.Tag.Get(tagMapKey) == "":
+		return fmt.Errorf("default value of field '%s' cannot be used: the field has no '%s' tag", path, tagMapKey)
+	case field.PkgPath != "":
+		return fmt.Errorf("default value of field '%s' cannot be used: unexported fields cannot be initialized", path)
+	case (isNested && !hasConverter) || fp.isStructList || fp.isMap:
+		return fmt.Errorf("default value of field '%s' cannot be used: fields of type '%s' do not support "+
+			"default values", path, field.Type)
+	}
+	err := v.setField(reflect.New(field.Type).Elem(), value, nil, fp.separator)
+	if err != nil {
+		return errors.Wrapf(err, "invalid default value '%s' for field '%s'", value, path)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDefaults_applyDefaults(t *testing.T) {
+	type Address struct {
+		City    string `datakey:"city" default:"Cluj"`
+		Country string `datakey:"country" default:"RO"`
+	}
+	type Item struct {
+		SKU string `datakey:"sku"`
+		Qty int    `datakey:"qty" default:"1"`
+	}
+	type MyStruct struct {
+		Timeout  time.Duration `datakey:"timeout" default:"30s"`
+		Billing  Address       `datakey:"billing"`
+		Shipping *Address      `datakey:"shipping"`
+		Items    []Item        `datakey:"items"`
+		Name     string        `datakey:"name" default:""`
+	}
+	testdata := []struct {
+		in  map[string]string
+		out map[string]string
+	}{
+		{
+			map[string]string{},
+			map[string]string{"timeout": "30s", "billing.city": "Cluj", "billing.country": "RO", "name": ""},
+		},
+		{
+			map[string]string{"timeout": "1m", "billing.city": "Iasi", "shipping.country": "MD", "name": "x"},
+			map[string]string{"timeout": "1m", "billing.city": "Iasi", "billing.country": "RO",
+				"shipping.city": "Cluj", "shipping.country": "MD", "name": "x"},
+		},
+		{
+			map[string]string{"items[0].sku": "A", "items[2].qty": "5", "name": "x"},
+			map[string]string{"timeout": "30s", "billing.city": "Cluj", "billing.country": "RO",
+				"items[0].sku": "A", "items[0].qty": "1", "items[2].qty": "5", "name": "x"},
+		},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestApplyDefaults_"+strconv.Itoa(i), func(t *testing.T) {
+			in := make(map[string]string)
+			for key, value := range td.in {
+				in[key] = value
+			}
+			result, err := v.applyDefaults(in, reflect.TypeOf(MyStruct{}))
+			if err != nil || !reflect.DeepEqual(result, td.out) || !reflect.DeepEqual(in, td.in) {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestDefaults_checkDefaults(t *testing.T) {
+	type Inner struct {
+		B int `datakey:"b" default:"x"`
+	}
+	type Node struct {
+		Children []Node `datakey:"children"`
+		A        int    `datakey:"a" default:"1"`
+	}
+	testdata := []struct {
+		in          reflect.Type
+		noErrorFlag bool
+	}{
+		{reflect.TypeOf(struct {
+			A int       `datakey:"a" default:"1"`
+			T time.Time `datakey:"t" default:"2019-01-01T00:00:00Z"`
+			S []int     `datakey:"s" default:"1,2"`
+			P *int      `datakey:"p" default:"3"`
+		}{}), true},
+		{reflect.TypeOf(Node{}), true},
+		{reflect.TypeOf(struct {
+			A int `datakey:"a" default:"x"`
+		}{}), false},
+		{reflect.TypeOf(struct {
+			I Inner
+		}{}), false},
+		{reflect.TypeOf(struct {
+			A int `default:"1"`
+		}{}), false},
+		{reflect.TypeOf(struct {
+			I Inner `datakey:"i" default:"1"`
+		}{}), false},
+		{reflect.TypeOf(struct {
+			M map[string]int `datakey:"m" default:"1"`
+		}{}), false},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestCheckDefaults_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkDefaults(td.in)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if cached := v.checkDefaults(td.in); cached != err {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestDefaults_ValidateAndInit(t *testing.T) {
+	type MyStruct struct {
+		Timeout time.Duration `datakey:"timeout" validate:"min=1" default:"30s"`
+		Retry   time.Duration `datakey:"retry" validate:"between=1s|1m" default:"5s"`
+	}
+	testdata := []struct {
+		in          map[string]string
+		out         MyStruct
+		noErrorFlag bool
+	}{
+		{map[string]string{}, MyStruct{Timeout: 30 * time.Second, Retry: 5 * time.Second}, true},
+		{map[string]string{"timeout": "1m", "retry": "1m"}, MyStruct{Timeout: time.Minute, Retry: time.Minute}, true},
+		{map[string]string{"retry": "500ms"}, MyStruct{}, false},
+		{map[string]string{"timeout": "-1s"}, MyStruct{}, false},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestDefaults_ValidateAndInit_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{}
+			err := v.ValidateAndInit(td.in, &s)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if s != td.out {
+				t.Error(s)
+			}
+			if err := v.ValidateStruct(s); err != nil && td.noErrorFlag {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,228 @@
+//This file contains the error types returned by the Validator
+//Validation and conversion failures are reported as FieldError values, which carry enough information to build
+//a response for the client without parsing the error message:
+//
+//		err := v.ValidateAndInit(m, &s)
+//		var fieldErr *validator.FieldError
+//		if errors.As(err, &fieldErr) {
+//			fmt.Println(fieldErr.Key, fieldErr.Rule)
+//		}
+
+package validator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+type (
+	//A failure of a single field, caused either by a rule or by the conversion of the map value
+	//Field is the path of the struct field (e.g. "Inner.C"), Key is the map key linked to it via the "datakey" tag,
+	//Rule is the name of the failed rule (empty for conversion failures), Value is the raw map value and Params are
+	//the rule params provided inside the tag
+	//Trigger is the map key that made a conditional rule apply (e.g. "country" for `validate:"required_if=country|RO"`)
+	//Err is the underlying cause of the failure
+	FieldError struct {
+		Field   string
+		Key     string
+		Rule    string
+		Value   string
+		Params  []string
+		Trigger string
+		Err     error
+	}
+
+	//Aggregated list of validation failures
+	//Returned when the Validator is configured to collect all the failures instead of stopping at the first one
+	//(see SetCollectAllErrors)
+	ValidationErrors []*FieldError
+
+	//A misconfiguration of a struct field found by Compile (e.g. an unknown rule, a malformed tag)
+	//Field is the path of the struct field and Key the map key linked to it (empty if the field has no "datakey" tag);
+	//the fields of the slices of sub structs have "[]" as index (e.g. "Items[].SKU", "items[].sku")
+	SchemaError struct {
+		Field string
+		Key   string
+		Err   error
+	}
+
+	//Aggregated list of the misconfigurations of a struct type, returned by Compile
+	SchemaErrors []*SchemaError
+
+	//A misconfiguration of a rule found by the builtin rules while applying it (e.g. a pattern that is not registered,
+	//a bound that is not a number)
+	//Unlike the failures, it is not caused by the map data, so it is returned right away as a plain error instead of
+	//being reported as a FieldError (see ruleConfigError)
+	configError struct {
+		err error
+	}
+)
+
+//Builds the error message from the field path, the map key, the rule and the underlying cause
+func (fe *FieldError) Error() string {
+	message := fmt.Sprintf("key '%s'", fe.Key)
+	if fe.Field != "" {
+		message = fmt.Sprintf("field '%s' (key '%s')", fe.Field, fe.Key)
+	}
+	if fe.Rule != "" {
+		message += fmt.Sprintf(" does not match rule '%s'", fe.Rule)
+	} else {
+		message += " could not be converted"
+	}
+	if fe.Err != nil {
+		message += ": " + fe.Err.Error()
+	}
+
+	return message
+}
+
+//Returns the underlying cause of the failure
+func (fe *FieldError) Unwrap() error {
+	return fe.Err
+}
+
+//Joins the messages of all the validation failures into a single message
+func (ve ValidationErrors) Error() string {
+	messages := make([]string, 0, len(ve))
+	for _, err := range ve {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+//Returns the list of failures so that errors.As and errors.Is can inspect each one of them (Go 1.20 and later)
+func (ve ValidationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(ve))
+	for _, err := range ve {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+//Finds the first failure that matches the target, so that errors.As can extract a *FieldError from the list with
+//the Go versions that do not unwrap the lists of errors (before Go 1.20)
+func (ve ValidationErrors) As(target interface{}) bool {
+	for _, err := range ve {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Checks if one of the failures matches the target, like As, for errors.Is
+func (ve ValidationErrors) Is(target error) bool {
+	for _, err := range ve {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Returns the message of the misconfiguration
+func (ce *configError) Error() string {
+	return ce.err.Error()
+}
+
+//Returns the misconfiguration found while applying the rule "rule" on the field "path" as a plain error, or nil if
+//"err" is a failure of the rule
+func ruleConfigError(err error, rule string, path string) error {
+	configErr, ok := err.(*configError)
+	if !ok {
+		return nil
+	}
+
+	return errors.Wrapf(configErr.err, "invalid rule '%s' for field '%s'", rule, path)
+}
+
+//Builds the error message from the field path, the map key and the misconfiguration
+func (se *SchemaError) Error() string {
+	message := fmt.Sprintf("field '%s'", se.Field)
+	if se.Key != "" {
+		message += fmt.Sprintf(" (key '%s')", se.Key)
+	}
+
+	return message + ": " + se.Err.Error()
+}
+
+//Returns the underlying misconfiguration
+func (se *SchemaError) Unwrap() error {
+	return se.Err
+}
+
+//Joins the messages of all the misconfigurations into a single message
+func (se SchemaErrors) Error() string {
+	messages := make([]string, 0, len(se))
+	for _, err := range se {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+//Returns the list of misconfigurations so that errors.As and errors.Is can inspect each one of them (Go 1.20 and
+//later)
+func (se SchemaErrors) Unwrap() []error {
+	errs := make([]error, 0, len(se))
+	for _, err := range se {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+//Finds the first misconfiguration that matches the target, so that errors.As can extract a *SchemaError from the
+//list with the Go versions that do not unwrap the lists of errors (before Go 1.20)
+func (se SchemaErrors) As(target interface{}) bool {
+	for _, err := range se {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Checks if one of the misconfigurations matches the target, like As, for errors.Is
+func (se SchemaErrors) Is(target error) bool {
+	for _, err := range se {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Converts the error returned by a rule or by a converter to a FieldError
+//If the error is already a FieldError, the information it does not provide is completed from the arguments,
+//otherwise the error becomes the cause of a new FieldError
+func toFieldError(err error, field string, key string, rule string, value string, params []string) *FieldError {
+	fieldErr, ok := err.(*FieldError)
+	if !ok {
+		fieldErr = &FieldError{Err: err}
+	}
+	if fieldErr.Field == "" {
+		fieldErr.Field = field
+	}
+	if fieldErr.Key == "" {
+		fieldErr.Key = key
+	}
+	if fieldErr.Rule == "" {
+		fieldErr.Rule = rule
+	}
+	if fieldErr.Value == "" {
+		fieldErr.Value = value
+	}
+	if fieldErr.Params == nil {
+		fieldErr.Params = params
+	}
+
+	return fieldErr
+}
@@ -0,0 +1,91 @@
+package validator
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"strconv"
+	"testing"
+)
+
+func TestErrors_FieldError(t *testing.T) {
+	testdata := []struct {
+		in  *FieldError
+		out string
+	}{
+		{
+			&FieldError{Key: "a", Rule: "required", Err: fmt.Errorf("missing")},
+			"key 'a' does not match rule 'required': missing",
+		},
+		{
+			&FieldError{Field: "IS.C", Key: "c", Rule: "int", Value: "asdf"},
+			"field 'IS.C' (key 'c') does not match rule 'int'",
+		},
+		{
+			&FieldError{Field: "B", Key: "b", Value: "asdf", Err: fmt.Errorf("bad value")},
+			"field 'B' (key 'b') could not be converted: bad value",
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestFieldError_"+strconv.Itoa(i), func(t *testing.T) {
+			if td.in.Error() != td.out {
+				t.Error()
+			}
+			if errors.Unwrap(td.in) != td.in.Err {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestErrors_ValidationErrors(t *testing.T) {
+	first := &FieldError{Key: "a", Rule: "required"}
+	second := &FieldError{Key: "b", Rule: "int"}
+	var err error = errors.Wrap(ValidationErrors{first, second}, "validation failed")
+
+	if err.Error() != "validation failed: key 'a' does not match rule 'required'; key 'b' does not match rule 'int'" {
+		t.Error()
+	}
+
+	var failures ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 2 {
+		t.Error()
+	}
+	var fieldErr *FieldError
+	if !errors.As(err, &fieldErr) || fieldErr != first {
+		t.Error()
+	}
+
+	//The As and Is methods are used by errors.As and errors.Is before Go 1.20, which do not unwrap the lists
+	cause := fmt.Errorf("cause")
+	third := &FieldError{Key: "c", Rule: "rule", Err: cause}
+	fieldErr = nil
+	if !(ValidationErrors{third}).As(&fieldErr) || fieldErr != third || (ValidationErrors{}).As(&fieldErr) {
+		t.Error()
+	}
+	if !(ValidationErrors{first, third}).Is(cause) || (ValidationErrors{first}).Is(cause) {
+		t.Error()
+	}
+
+	var schemaErr *SchemaError
+	schemaErrs := SchemaErrors{{Field: "A", Err: cause}}
+	if !schemaErrs.As(&schemaErr) || schemaErr != schemaErrs[0] || !schemaErrs.Is(cause) || schemaErrs.As(&fieldErr) {
+		t.Error()
+	}
+}
+
+func TestErrors_toFieldError(t *testing.T) {
+	cause := fmt.Errorf("cause")
+	fieldErr := toFieldError(cause, "A", "a", "rule", "value", []string{"1"})
+	if fieldErr.Err != cause || fieldErr.Field != "A" || fieldErr.Key != "a" || fieldErr.Rule != "rule" ||
+		fieldErr.Value != "value" || len(fieldErr.Params) != 1 {
+		t.Error()
+	}
+
+	existing := &FieldError{Key: "b", Rule: "int"}
+	fieldErr = toFieldError(existing, "A", "a", "rule", "value", nil)
+	if fieldErr != existing || fieldErr.Field != "A" || fieldErr.Key != "b" || fieldErr.Rule != "int" ||
+		fieldErr.Value != "value" {
+		t.Error()
+	}
+}
@@ -0,0 +1,201 @@
+//This file contains the support for struct level validation: after the initialization, the structs implementing
+//Validatable or ValidatableWith get the chance to check the invariants that involve several fields
+//
+//		func (p *Period) Validate() error {
+//			if p.End.Sub(p.Start) > 24*time.Hour {
+//				return &validator.FieldError{Field: "End", Key: "end", Err: fmt.Errorf("period too long")}
+//			}
+//			return nil
+//		}
+//
+//The methods are called bottom-up: the sub structs (and the elements of the slices of sub structs) are validated
+//before the struct that contains them
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type (
+	//Implemented by the structs that validate themselves after the initialization
+	//The returned error is reported as a failure of the struct: FieldError and ValidationErrors values keep their
+	//information, with the field paths and the map keys completed with the position of the struct (e.g. "City" inside
+	//"Billing" becomes "Billing.City"), while any other error becomes a FieldError with the rule "validate"
+	Validatable interface {
+		Validate() error
+	}
+
+	//Same as Validatable, for the structs that need the Validator (e.g. to validate some other data with it)
+	//If a struct implements both interfaces, only ValidateWith is called
+	ValidatableWith interface {
+		ValidateWith(v *Validator) error
+	}
+)
+
+//The names of the hook methods
+const (
+	hookValidate     = "Validate"
+	hookValidateWith = "ValidateWith"
+)
+
+//The types of the hook interfaces
+var (
+	validatableType     = reflect.TypeOf((*Validatable)(nil)).Elem()
+	validatableWithType = reflect.TypeOf((*ValidatableWith)(nil)).Elem()
+)
+
+//Calls the Validate and ValidateWith methods of the initialized struct "t" and of its sub structs, bottom-up
+//When the Validator collects all the errors, the failures are returned together as ValidationErrors
+func (v *Validator) callHooks(t reflect.Value) error {
+	failures := ValidationErrors{}
+	err := v.callStructHooks(t, structPosition{parents: []reflect.Type{t.Type()}}, &failures)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+//Calls the Validate and ValidateWith methods of the struct found at "position" after the ones of its sub structs and
+//of the elements of its slices of sub structs
+func (v *Validator) callStructHooks(t reflect.Value, position structPosition, failures *ValidationErrors) error {
+	err := v.callFieldHooks(t, position, failures)
+	if err != nil {
+		return err
+	}
+
+	return v.callHook(t, position, failures)
+}
+
+//Calls the Validate and ValidateWith methods of the sub structs and of the elements of the slices of sub structs of the
+//struct found at "position"
+//The method of an embedded struct that is promoted to the struct is not called on the embedded struct, since it is
+//called on the struct itself (see fieldPlan.hasPromotedHook)
+func (v *Validator) callFieldHooks(t reflect.Value, position structPosition, failures *ValidationErrors) error {
+	for _, fp := range v.plan(t.Type()).fields {
+		field := fp.field
+		value := t.Field(fp.index)
+		if nestedType, ok := position.nested(field); ok && !(field.Type.Kind() == reflect.Ptr && value.IsNil()) {
+			callHooks := v.callStructHooks
+			if fp.hasPromotedHook {
+				callHooks = v.callFieldHooks
+			}
+			err := callHooks(reflect.Indirect(value), position.child(field, nestedType), failures)
+			if err != nil {
+				return err
+			}
+		}
+
+		mapKey := position.key(field)
+		if fp.isStructList && mapKey != "" {
+			for elementIndex := 0; elementIndex < value.Len(); elementIndex++ {
+				elementValue := reflect.Indirect(value.Index(elementIndex))
+				if !elementValue.IsValid() {
+					continue
+				}
+				element := mapElement{index: elementIndex, prefix: fmt.Sprintf("%s[%d]", mapKey, elementIndex)}
+				elementPosition := position.with(field, mapKey).element(element, fp.elementType)
+				err := v.callStructHooks(elementValue, elementPosition, failures)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+//Checks if the hook method of an embedded struct (the one called by callHook) is also the hook method of the struct
+//type "t" that embeds it, either promoted or declared by "t" itself: the method is then only called on the struct, so
+//a method declared by the struct must call the one of the embedded struct itself
+func promotesHook(t reflect.Type, field reflect.StructField) bool {
+	embeddedType := fieldType(field)
+	if !field.Anonymous || embeddedType.Kind() != reflect.Struct {
+		return false
+	}
+	name := hookName(embeddedType)
+
+	return name != "" && name == hookName(t)
+}
+
+//Returns the name of the hook method called by callHook on the structs of type "t" (through a pointer), or an empty
+//string if the type implements neither Validatable nor ValidatableWith
+func hookName(t reflect.Type) string {
+	switch pointerType := reflect.PtrTo(t); {
+	case pointerType.Implements(validatableWithType):
+		return hookValidateWith
+	case pointerType.Implements(validatableType):
+		return hookValidate
+	default:
+		return ""
+	}
+}
+
+//Calls the Validate or ValidateWith method of the struct found at "position", if it implements one of them
+func (v *Validator) callHook(t reflect.Value, position structPosition, failures *ValidationErrors) error {
+	target := t
+	if t.CanAddr() {
+		target = t.Addr()
+	}
+	if !target.CanInterface() {
+		return nil
+	}
+
+	var err error
+	switch hook := target.Interface().(type) {
+	case ValidatableWith:
+		err = hook.ValidateWith(v)
+	case Validatable:
+		err = hook.Validate()
+	default:
+		return nil
+	}
+
+	switch hookErr := err.(type) {
+	case nil:
+		return nil
+	case ValidationErrors:
+		for _, fieldErr := range hookErr {
+			if err := v.reportFailure(hookFailure(fieldErr, position), failures); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return v.reportFailure(hookFailure(err, position), failures)
+	}
+}
+
+//Converts an error returned by the Validate or ValidateWith method of the struct found at "position" to a FieldError
+//The field path and the map key of the FieldError are completed with the position of the struct and the rule is
+//"validate" if not provided
+func hookFailure(err error, position structPosition) *FieldError {
+	fieldErr, ok := err.(*FieldError)
+	if !ok {
+		fieldErr = &FieldError{Err: err}
+	}
+
+	result := *fieldErr
+	switch {
+	case result.Field == "":
+		result.Field = position.path
+	case position.path != "":
+		result.Field = fieldPath(position.path, result.Field)
+	}
+	if result.Key == "" {
+		result.Key = position.prefix
+	} else {
+		result.Key = resolveKey(position.prefix, result.Key)
+	}
+	if result.Rule == "" {
+		result.Rule = ruleValidate
+	}
+
+	return &result
+}
@@ -0,0 +1,167 @@
+//This file contains the compiled plans of the struct types
+//The information the Validator needs about the fields of a struct type (tags, parsed rules, resolved rule functions,
+//kind of field) is computed the first time the type is processed and cached inside the Validator, so the following
+//calls do not read and parse the tags again
+//
+//The plans depend on the registries of the Validator, so they are dropped whenever a rule or a converter is registered
+//A plan compiled while a registration takes place is not stored, since it may have been built from the old registries
+
+package validator
+
+import (
+	"reflect"
+	"sync"
+)
+
+type (
+	//A rule of a field, resolved against the registries of the Validator
+	//At most one of "rule", "fieldRule" and "valueRule" is set, following the precedence of the rules: the rules
+	//registered with RegisterRule, the builtin field rules and the value rules; none is set if the rule has no
+	//implementation
+	//"structRule" is the value based implementation used by ValidateStruct: a value rule or a builtin struct rule
+	//"checkParams" checks the params of a builtin rule against the type of the values the rule is applied on; it is
+	//nil for the custom rules
+	compiledRule struct {
+		tagRule
+		rule        func(mapKey string, m map[string]string, params ...string) error
+		fieldRule   func(fc fieldContext, params ...string) error
+		valueRule   func(value reflect.Value, lookup FieldLookup, params ...string) error
+		structRule  func(value reflect.Value, lookup FieldLookup, params ...string) error
+		checkParams func(t reflect.Type, params ...string) error
+	}
+
+	//The compiled information about a struct field
+	//"rules" are the rules applied on the field and "elementRules" the ones following the "dive" modifier; for map
+	//fields, the latter are split into "keyRules" and "valueRules" (see splitKeys)
+	//"hasPromotedHook" is true for the embedded structs whose Validate or ValidateWith method is part of the method set
+	//of the struct that embeds them (see promotesHook)
+	//The errors found while parsing the tags are kept and returned when the field is processed
+	fieldPlan struct {
+		index           int
+		field           reflect.StructField
+		separator       string
+		hasRules        bool
+		rules           []compiledRule
+		elementRules    []compiledRule
+		keyRules        []compiledRule
+		valueRules      []compiledRule
+		isDive          bool
+		rulesErr        error
+		keysErr         error
+		hasTransformers bool
+		transformers    []tagRule
+		transformersErr error
+		elementType     reflect.Type
+		isStructList    bool
+		isMap           bool
+		hasPromotedHook bool
+	}
+
+	//The compiled information about a struct type: the plans of its fields, in the order of the fields
+	structPlan struct {
+		fields []fieldPlan
+	}
+)
+
+//Returns the plan of a struct type, compiling it the first time the type is processed
+func (v *Validator) plan(t reflect.Type) *structPlan {
+	if plan, ok := v.plans.Load(t); ok {
+		return plan.(*structPlan)
+	}
+
+	generation := v.registryGeneration()
+	plan := v.compilePlan(t)
+	v.storeCached(&v.plans, t, plan, generation)
+
+	return plan
+}
+
+//Compiles the plan of a struct type
+func (v *Validator) compilePlan(t reflect.Type) *structPlan {
+	plan := &structPlan{fields: make([]fieldPlan, t.NumField())}
+	for index := range plan.fields {
+		field := t.Field(index)
+		fp := fieldPlan{index: index, field: field, separator: fieldSeparator(field)}
+		fp.elementType, fp.isStructList = v.structElements(field)
+		fp.isMap = v.isMapField(field)
+		fp.hasPromotedHook = promotesHook(t, field)
+
+		var validationRules string
+		if validationRules, fp.hasRules = field.Tag.Lookup(tagValidate); fp.hasRules {
+			rules, err := parseRules(validationRules)
+			fp.rulesErr = err
+			fieldRules, elementRules, isDive := splitDive(rules)
+			fp.rules, fp.elementRules, fp.isDive = v.compileRules(fieldRules), v.compileRules(elementRules), isDive
+			if fieldType(field).Kind() == reflect.Map && isDive {
+				keyRules, valueRules, _, err := splitKeys(elementRules)
+				fp.keyRules, fp.valueRules, fp.keysErr = v.compileRules(keyRules), v.compileRules(valueRules), err
+			}
+		}
+
+		var transformers string
+		if transformers, fp.hasTransformers = field.Tag.Lookup(tagTransform); fp.hasTransformers {
+			fp.transformers, fp.transformersErr = parseRules(transformers)
+		}
+		plan.fields[index] = fp
+	}
+
+	return plan
+}
+
+//Resolves a list of rules against the registries of the Validator
+func (v *Validator) compileRules(rules []tagRule) []compiledRule {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		cr := compiledRule{tagRule: rule}
+		if ruleImpl, ok := v.ruleMappings[rule.name]; ok {
+			cr.rule = ruleImpl
+		} else if fieldRuleImpl, ok := v.fieldRuleMappings[rule.name]; ok {
+			cr.fieldRule = fieldRuleImpl
+		} else if valueRuleImpl, ok := v.valueRuleMappings[rule.name]; ok {
+			cr.valueRule = valueRuleImpl
+		}
+		if valueRuleImpl, ok := v.valueRuleMappings[rule.name]; ok {
+			cr.structRule = valueRuleImpl
+		} else if structRuleImpl, ok := v.structRuleMappings[rule.name]; ok {
+			cr.structRule = structRuleImpl
+		}
+		cr.checkParams = v.paramCheckers[rule.name]
+		compiled = append(compiled, cr)
+	}
+
+	return compiled
+}
+
+//Checks if a rule has an implementation
+func (cr compiledRule) isDefined() bool {
+	return cr.rule != nil || cr.fieldRule != nil || cr.valueRule != nil
+}
+
+//Stores a value inside one of the caches of the Validator, unless a registration took place since "generation" was
+//read: the value may have been computed from the old registries
+func (v *Validator) storeCached(cache *sync.Map, key interface{}, value interface{}, generation uint64) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	if v.generation == generation {
+		cache.Store(key, value)
+	}
+}
+
+//Drops the cached information after a change of the registries; the caller must hold the write lock
+func (v *Validator) invalidate() {
+	v.generation++
+	v.resetPlans()
+}
+
+//Removes the compiled plans and the other information cached for each type (the resolved converters and the checks
+//of the default values), since they depend on the registries of the Validator
+func (v *Validator) resetPlans() {
+	for _, cache := range []*sync.Map{&v.plans, &v.converterCache, &v.defaultErrors} {
+		cache.Range(func(key, value interface{}) bool {
+			cache.Delete(key)
+			return true
+		})
+	}
+}
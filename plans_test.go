@@ -0,0 +1,182 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestPlans_plan(t *testing.T) {
+	type Address struct {
+		City string `datakey:"city" validate:"required"`
+	}
+	type MyStruct struct {
+		A       string            `datakey:"a" validate:"required,minlen=2,eqfield=b,dive,rule" transform:"trim"`
+		B       []string          `datakey:"b" datasep:";"`
+		C       map[string]string `datakey:"c" validate:"dive,keys,minlen=2,endkeys,required"`
+		D       []Address         `datakey:"d"`
+		E       string            `datakey:"e" validate:"required,=1" transform:"=1"`
+		private string
+	}
+
+	v := New()
+	plan := v.plan(reflect.TypeOf(MyStruct{}))
+	if cached := v.plan(reflect.TypeOf(MyStruct{})); cached != plan {
+		t.Error()
+	}
+	if len(plan.fields) != 6 {
+		t.Fatal()
+	}
+
+	a, b, c, d, e := plan.fields[0], plan.fields[1], plan.fields[2], plan.fields[3], plan.fields[4]
+	if !a.hasRules || !a.isDive || len(a.rules) != 3 || len(a.elementRules) != 1 || a.rulesErr != nil {
+		t.Error()
+	}
+	if a.rules[0].rule == nil || a.rules[1].fieldRule == nil || a.rules[2].valueRule == nil {
+		t.Error()
+	}
+	if a.elementRules[0].isDefined() || !a.hasTransformers || len(a.transformers) != 1 {
+		t.Error()
+	}
+	if b.hasRules || b.separator != ";" || b.isStructList || b.isMap {
+		t.Error()
+	}
+	if !c.isMap || len(c.keyRules) != 1 || len(c.valueRules) != 1 || c.keysErr != nil {
+		t.Error()
+	}
+	if !d.isStructList || d.elementType != reflect.TypeOf(Address{}) {
+		t.Error()
+	}
+	if e.rulesErr == nil || e.transformersErr == nil {
+		t.Error()
+	}
+	if plan.fields[5].index != 5 || plan.fields[5].field.Name != "private" {
+		t.Error()
+	}
+}
+
+func TestPlans_resetPlans(t *testing.T) {
+	type MyStruct struct {
+		A string `datakey:"a" validate:"rule"`
+		B int    `datakey:"b" default:"1"`
+	}
+
+	testData := []struct {
+		register    func(v *Validator) error
+		m           map[string]string
+		noErrorFlag bool
+	}{
+		{
+			register:    func(v *Validator) error { return nil },
+			m:           map[string]string{"a": "value"},
+			noErrorFlag: true,
+		},
+		{
+			register: func(v *Validator) error {
+				return v.RegisterRule("rule", func(mapKey string, m map[string]string, params ...string) error {
+					return fmt.Errorf("invalid value")
+				})
+			},
+			m:           map[string]string{"a": "value"},
+			noErrorFlag: false,
+		},
+		{
+			register: func(v *Validator) error {
+				return v.RegisterValueRule("rule", func(value reflect.Value, lookup FieldLookup,
+					params ...string) error {
+					return fmt.Errorf("invalid value")
+				})
+			},
+			m:           map[string]string{"a": "value"},
+			noErrorFlag: false,
+		},
+		{
+			register: func(v *Validator) error {
+				return v.RegisterConverter("int", func(value string, params ...string) (interface{}, error) {
+					return nil, fmt.Errorf("invalid value")
+				})
+			},
+			m:           map[string]string{"a": "value", "b": "1"},
+			noErrorFlag: false,
+		},
+	}
+
+	for i, td := range testData {
+		t.Run("TestPlans_resetPlans_"+strconv.Itoa(i), func(t *testing.T) {
+			v := New()
+			err := v.RegisterValueRule("rule", func(value reflect.Value, lookup FieldLookup, params ...string) error {
+				return nil
+			})
+			if err != nil {
+				t.Error()
+			}
+			err = v.ValidateAndInit(map[string]string{"a": "value", "b": "1"}, &MyStruct{})
+			if err != nil {
+				t.Error()
+			}
+
+			err = td.register(v)
+			if err != nil {
+				t.Error()
+			}
+			err = v.ValidateAndInit(td.m, &MyStruct{})
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+type benchAddress struct {
+	City    string `datakey:"city" validate:"required,minlen=2" transform:"trim"`
+	Country string `datakey:"country" validate:"required,oneof=RO|MD|US"`
+	State   string `datakey:"state" validate:"required_if=country|US"`
+}
+
+type benchItem struct {
+	SKU string `datakey:"sku" validate:"required,regex=^[A-Z]{3}-[0-9]{4}$"`
+	Qty int    `datakey:"qty" validate:"int,between=1|100" default:"1"`
+}
+
+type benchOrder struct {
+	ID      int               `datakey:"id" validate:"required,int,min=1"`
+	Email   string            `datakey:"email" validate:"required,email" transform:"trim,lower"`
+	Start   time.Time         `datakey:"start" validate:"required,time"`
+	End     time.Time         `datakey:"end" validate:"required,time,gtfield=start"`
+	Tags    []string          `datakey:"tags" validate:"maxlen=5,dive,minlen=2"`
+	Labels  map[string]string `datakey:"label" validate:"dive,required"`
+	Billing benchAddress      `datakey:"billing"`
+	Items   []benchItem       `datakey:"items" validate:"required"`
+}
+
+var benchData = map[string]string{
+	"id": "42", "email": " John@Example.com ", "start": "2019-08-21T09:00:00Z", "end": "2019-08-22T09:00:00Z",
+	"tags": "ab,cd,ef", "label.env": "prod", "label.team": "core", "billing.city": " Cluj ", "billing.country": "RO",
+	"items[0].sku": "ABC-1234", "items[0].qty": "2", "items[1].sku": "DEF-5678",
+}
+
+func BenchmarkPlans_ValidateAndInit(b *testing.B) {
+	v := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := v.ValidateAndInit(benchData, &benchOrder{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+//Drops the cached plans before each call, to measure the cost of processing the struct type from scratch
+func BenchmarkPlans_ValidateAndInitUncached(b *testing.B) {
+	v := New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		v.resetPlans()
+		if err := v.ValidateAndInit(benchData, &benchOrder{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
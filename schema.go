@@ -0,0 +1,243 @@
+//This file contains the upfront checking of the struct types
+//
+//Compile checks a struct type against the registries of the Validator before any data is processed, so that the
+//misconfigurations (unknown rules and transformers, malformed tags, missing converters, invalid default values) are
+//found at startup instead of when a request contains the affected keys:
+//
+//		var orderSchema = validator.GetInstance().MustCompile(&Order{})
+//
+//		func handle(m map[string]string) error {
+//			order := Order{}
+//			return orderSchema.ValidateAndInit(m, &order)
+//		}
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//A struct type checked by Compile, bound to the Validator that checked it
+//A Schema can be shared between goroutines; the rules and the converters registered after Compile are not checked
+type Schema struct {
+	validator *Validator
+	t         reflect.Type
+}
+
+//Checks the struct type of "i" (a pointer to a struct, e.g. &MyStruct{} or (*MyStruct)(nil), since only the type is
+//needed) and of its sub structs against the registries of the Validator and returns a Schema for it
+//Every misconfiguration is reported, as SchemaErrors:
+//* malformed "validate" and "transform" tags, or modifiers used on fields of the wrong type (e.g. "dive" on a string)
+//* rules and transformers with no implementation
+//* malformed params of the builtin rules (e.g. "min=abc", "regex=[a-", "pattern=" with an unregistered pattern) and
+//comparison rules referencing keys that are not linked to any field
+//* fields linked to a map key whose type has no converter, or that cannot be initialized (e.g. unexported fields)
+//* default values that cannot be used or converted to the type of their field
+//The optional sub structs and the slices of sub structs are checked even though their keys may never be present
+func (v *Validator) Compile(i interface{}) (*Schema, error) {
+	t := reflect.TypeOf(i)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("please provide a pointer to the struct")
+	}
+	if !v.isInit {
+		return nil, fmt.Errorf("validator not initialized: call New()")
+	}
+	t = t.Elem()
+
+	//The keys linked to the fields, referenced by the comparison rules
+	keys := make(map[string]bool)
+	_ = v.walkTypes(t, structPosition{parents: []reflect.Type{t}},
+		func(fp fieldPlan, position structPosition) error {
+			if mapKey := position.key(fp.field); mapKey != "" && fp.field.PkgPath == "" {
+				keys[mapKey] = true
+			}
+			return nil
+		})
+
+	failures := SchemaErrors{}
+	_ = v.walkTypes(t, structPosition{parents: []reflect.Type{t}},
+		func(fp fieldPlan, position structPosition) error {
+			for _, err := range v.checkFieldSchema(fp, position, keys) {
+				failures = append(failures, &SchemaError{Field: fieldPath(position.path, fp.field.Name),
+					Key: position.key(fp.field), Err: err})
+			}
+			return nil
+		})
+	if len(failures) > 0 {
+		return nil, failures
+	}
+
+	return &Schema{validator: v, t: t}, nil
+}
+
+//Same as Compile, but panics if the struct type is misconfigured
+//Meant for the initialization of package level variables
+func (v *Validator) MustCompile(i interface{}) *Schema {
+	schema, err := v.Compile(i)
+	if err != nil {
+		panic(err)
+	}
+
+	return schema
+}
+
+//Returns the struct type of the Schema
+func (s *Schema) Type() reflect.Type {
+	return s.t
+}
+
+//Validates the map data and initializes the struct "i" with it, like Validator.ValidateAndInit
+//The parameter "i" must be a pointer to a struct of the Schema type
+func (s *Schema) ValidateAndInit(m map[string]string, i interface{}) error {
+	t, err := s.target(i)
+	if err != nil {
+		return err
+	}
+
+	return s.validator.validateAndInit(m, nil, t, nil)
+}
+
+//Validates the map data without initializing the struct "i", like Validator.Validate
+//The parameter "i" must be a pointer to a struct of the Schema type
+func (s *Schema) Validate(m map[string]string, i interface{}) error {
+	if _, err := s.target(i); err != nil {
+		return err
+	}
+
+	return s.validator.Validate(m, i)
+}
+
+//Initializes the struct "i" with the map data without validating it, like Validator.Init
+//The parameter "i" must be a pointer to a struct of the Schema type
+func (s *Schema) Init(m map[string]string, i interface{}) error {
+	if _, err := s.target(i); err != nil {
+		return err
+	}
+
+	return s.validator.Init(m, i)
+}
+
+//Validates the map data based on the rules of the Schema type, like Validator.Check
+func (s *Schema) Check(m map[string]string) error {
+	return s.validator.Check(m, s)
+}
+
+//Checks the interface provided to the Schema methods and returns the struct it points to
+func (s *Schema) target(i interface{}) (reflect.Value, error) {
+	if value := reflect.ValueOf(i); value.Kind() != reflect.Ptr || value.Type().Elem() != s.t {
+		return reflect.Value{}, fmt.Errorf("please provide a pointer to a struct of type '%s'", s.t)
+	}
+
+	return s.validator.target(i)
+}
+
+//Returns the misconfigurations of a field of the struct found at "position"
+//"keys" contains the map keys linked to the fields of the compiled struct type
+func (v *Validator) checkFieldSchema(fp fieldPlan, position structPosition, keys map[string]bool) []error {
+	var errs []error
+	path := fieldPath(position.path, fp.field.Name)
+	if fp.hasRules {
+		if err := checkRuleTag(fp, path); err != nil {
+			errs = append(errs, err)
+		}
+		//The rules are checked against the type of the values they are applied on: the field type, the key type for
+		//the rules between "keys" and "endkeys" and the element type for the other rules following "dive"
+		//The "keys" and "endkeys" modifiers of map fields are removed from their element rules (see splitKeys)
+		checkRules := func(rules []compiledRule, t reflect.Type) {
+			for _, rule := range rules {
+				if err := checkRuleSchema(rule, t, position, keys); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		}
+		checkRules(fp.rules, fieldType(fp.field))
+		if fieldType(fp.field).Kind() == reflect.Map {
+			checkRules(fp.keyRules, fieldType(entryKeyField(fp.field)))
+			checkRules(fp.valueRules, fieldType(elementField(fp.field)))
+		} else {
+			checkRules(fp.elementRules, fieldType(elementField(fp.field)))
+		}
+	}
+
+	if fp.transformersErr != nil {
+		errs = append(errs, fp.transformersErr)
+	}
+	for _, transformer := range fp.transformers {
+		if _, ok := v.lookupTransformer(transformer.name); !ok {
+			errs = append(errs, fmt.Errorf("transformer '%s' has no implementation. "+
+				"please use 'RegisterTransformer' to provide one", transformer.name))
+		}
+	}
+
+	if err := v.checkFieldDefault(fp, position); err != nil {
+		errs = append(errs, err)
+	}
+	if err := v.checkFieldConversion(fp, position); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+//Checks that a rule has an implementation and that its params fit the type "t" of the values it is applied on
+//The keys referenced by the comparison rules must be linked to a field of the struct type ("keys")
+func checkRuleSchema(rule compiledRule, t reflect.Type, position structPosition, keys map[string]bool) error {
+	if rule.name == ruleKeys || rule.name == ruleEndKeys {
+		return nil
+	}
+	if !rule.isDefined() {
+		return fmt.Errorf("validation rule '%s' has no implementation. "+
+			"please use 'RegisterRule' to provide one", rule.name)
+	}
+	if rule.checkParams == nil {
+		return nil
+	}
+	if err := rule.checkParams(t, rule.params...); err != nil {
+		return fmt.Errorf("invalid params for rule '%s': %v", rule.name, err)
+	}
+	if isComparisonRule(rule.name) && !keys[resolveKey(position.prefix, rule.params[0])] {
+		return fmt.Errorf("rule '%s' references key '%s', which is not linked to any field", rule.name,
+			rule.params[0])
+	}
+
+	return nil
+}
+
+//Checks that a field linked to a map key can be initialized: the field is exported and its type (or the key and the
+//element types for map fields) can be converted from map values
+//The sub structs and the slices of sub structs are not converted themselves, their fields are checked instead
+func (v *Validator) checkFieldConversion(fp fieldPlan, position structPosition) error {
+	field := fp.field
+	_, isNested := position.nested(field)
+	_, _, hasConverter := v.resolveConverter(fieldType(field))
+	if position.key(field) == "" || (isNested && !hasConverter) || fp.isStructList {
+		return nil
+	}
+	if field.PkgPath != "" {
+		return fmt.Errorf("unexported fields cannot be initialized")
+	}
+	if fp.isMap {
+		if _, _, ok := v.resolveConverter(field.Type.Key()); !ok {
+			return fmt.Errorf("conversion to '%s' is not defined, please use RegisterConverter", field.Type.Key())
+		}
+		return v.checkConversion(field.Type.Elem())
+	}
+
+	return v.checkConversion(field.Type)
+}
+
+//Checks that values of the given type can be built from map values (see setField)
+func (v *Validator) checkConversion(t reflect.Type) error {
+	if _, _, ok := v.resolveConverter(t); ok {
+		return nil
+	}
+	switch {
+	case t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Ptr:
+		return fmt.Errorf("conversion to '%s' is not supported: pointers to pointers cannot be initialized", t)
+	case t.Kind() == reflect.Ptr || isSequence(t):
+		return v.checkConversion(t.Elem())
+	default:
+		return fmt.Errorf("conversion to '%s' is not defined, please use RegisterConverter", t)
+	}
+}
@@ -0,0 +1,295 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSchema_Compile(t *testing.T) {
+	type Inner struct {
+		A string `datakey:"a" validate:"unknown"`
+	}
+	type Item struct {
+		SKU string `datakey:"sku" validate:"required,dive,minlen=1"`
+	}
+	type ValidInner struct {
+		A string `datakey:"a" validate:"required"`
+	}
+	type Valid struct {
+		A     string            `datakey:"a" validate:"required,minlen=2,eqfield=b" transform:"trim"`
+		B     string            `datakey:"b" default:"value"`
+		C     *ValidInner       `datakey:"c" validate:"required_with=a"`
+		D     time.Time         `datakey:"d" validate:"time"`
+		E     []int             `datakey:"e" validate:"dive,min=1"`
+		F     map[string]*int   `datakey:"f" validate:"dive,keys,minlen=2,endkeys,min=1"`
+		Items []struct{ S int } `datakey:"items"`
+		inner ValidInner
+	}
+	type Invalid struct {
+		A     string            `datakey:"a" validate:"required,unknown" transform:"trim,unknown"`
+		B     int               `datakey:"b" default:"value"`
+		C     *Inner            `datakey:"c"`
+		D     chan int          `datakey:"d"`
+		E     string            `datakey:"e" validate:"dive,required"`
+		F     map[string]string `datakey:"f" validate:"dive,keys,required"`
+		G     string            `datakey:"g" validate:"=1" transform:"=1"`
+		Items []Item            `datakey:"items"`
+		h     string            `datakey:"h"`
+	}
+
+	testData := []struct {
+		i           interface{}
+		failures    []string
+		noErrorFlag bool
+	}{
+		{&Valid{}, nil, true},
+		{(*Valid)(nil), nil, true},
+		{Valid{}, nil, false},
+		{nil, nil, false},
+		{new(int), nil, false},
+		{&Invalid{}, []string{"A", "A", "B", "C.A", "D", "E", "F", "G", "G", "Items[].SKU", "h"}, false},
+	}
+
+	v := New()
+	for i, td := range testData {
+		t.Run("TestSchema_Compile_"+strconv.Itoa(i), func(t *testing.T) {
+			schema, err := v.Compile(td.i)
+			if td.noErrorFlag && (err != nil || schema.Type() != reflect.TypeOf(Valid{})) {
+				t.Fatal(err)
+			}
+			if !td.noErrorFlag && (err == nil || schema != nil) {
+				t.Error()
+			}
+			if td.failures == nil {
+				return
+			}
+			failures, ok := err.(SchemaErrors)
+			if !ok || len(failures) != len(td.failures) {
+				t.Fatal(err)
+			}
+			for index, failure := range failures {
+				if failure.Field != td.failures[index] {
+					t.Error(failure)
+				}
+			}
+		})
+	}
+}
+
+func TestSchema_Compile2(t *testing.T) {
+	type Item struct {
+		SKU string `datakey:"sku" validate:"required,unknown"`
+	}
+	type MyStruct struct {
+		Items []Item `datakey:"items"`
+	}
+
+	v := New()
+	_, err := v.Compile(&MyStruct{})
+	var schemaErr *SchemaError
+	if !errors.As(err, &schemaErr) || schemaErr.Field != "Items[].SKU" || schemaErr.Key != "items[].sku" {
+		t.Fatal(err)
+	}
+
+	_ = v.RegisterRule("unknown", func(mapKey string, m map[string]string, params ...string) error {
+		return nil
+	})
+	_, err = v.Compile(&MyStruct{})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSchema_Compile3(t *testing.T) {
+	type Inner struct {
+		From int `datakey:"from"`
+		To   int `datakey:"to" validate:"gtfield=from"`
+		Max  int `datakey:"max" validate:"ltfield=limit"`
+	}
+
+	testData := []struct {
+		i           interface{}
+		noErrorFlag bool
+	}{
+		{&struct {
+			A int           `datakey:"a" validate:"min=1,max=1.5,between=-5|-1"`
+			B string        `datakey:"b" validate:"len=2,minlen=1,maxlen=3,uuid=4,regex=^(a|b)$,oneof=a|b"`
+			C string        `datakey:"c" validate:"required_if=a 1 b x,required_with=a b,eqfield=b,pattern=sku"`
+			D time.Duration `datakey:"d" validate:"min=1s,between=1s|1m,max=100"`
+			E []string      `datakey:"e" validate:"maxlen=2,dive,minlen=1"`
+		}{}, true},
+		{&struct {
+			A int `datakey:"a" validate:"min=abc"`
+		}{}, false},
+		{&struct {
+			A int `datakey:"a" validate:"between=1"`
+		}{}, false},
+		{&struct {
+			A int `datakey:"a" validate:"between=5|1"`
+		}{}, false},
+		{&struct {
+			A int `datakey:"a" validate:"min=1m"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"regex=[a-"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"uuid=9"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"required_if=x"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"required_with"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"eqfield"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"eqfield=b"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"pattern=nope"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"required=1"`
+		}{}, false},
+		{&struct {
+			A string `datakey:"a" validate:"oneof"`
+		}{}, false},
+		{&struct {
+			A []string `datakey:"a" validate:"dive,maxlen=x"`
+		}{}, false},
+		{&struct {
+			A map[string]int `datakey:"a" validate:"dive,keys,minlen=x,endkeys"`
+		}{}, false},
+		{&struct {
+			I Inner `datakey:"inner"`
+		}{}, false},
+		{&struct {
+			I     []Inner `datakey:"items"`
+			Limit int     `datakey:"limit"`
+		}{}, false},
+	}
+
+	v := New()
+	_ = v.RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$")
+	for i, td := range testData {
+		t.Run("TestSchema_Compile3_"+strconv.Itoa(i), func(t *testing.T) {
+			_, err := v.Compile(td.i)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			var schemaErr *SchemaError
+			if !td.noErrorFlag && (!errors.As(err, &schemaErr) || len(err.(SchemaErrors)) != 1) {
+				t.Error(err)
+			}
+		})
+	}
+
+	//The params of the custom rules are not checked, even when they replace a builtin rule
+	_ = v.RegisterRule("min", func(mapKey string, m map[string]string, params ...string) error {
+		return nil
+	})
+	if _, err := v.Compile(testData[1].i); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSchema_MustCompile(t *testing.T) {
+	type MyStruct struct {
+		A string `datakey:"a" validate:"unknown"`
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error()
+		}
+	}()
+	New().MustCompile(&MyStruct{})
+}
+
+func TestSchema_ValidateAndInit(t *testing.T) {
+	type MyStruct struct {
+		A int    `datakey:"a" validate:"required,int"`
+		B string `datakey:"b" default:"value"`
+	}
+	type Other struct {
+		A int `datakey:"a"`
+	}
+
+	testData := []struct {
+		m           map[string]string
+		i           interface{}
+		noErrorFlag bool
+	}{
+		{map[string]string{"a": "1"}, &MyStruct{}, true},
+		{map[string]string{"a": "x"}, &MyStruct{}, false},
+		{map[string]string{"a": "1"}, MyStruct{}, false},
+		{map[string]string{"a": "1"}, (*MyStruct)(nil), false},
+		{map[string]string{"a": "1"}, &Other{}, false},
+	}
+
+	schema := New().MustCompile((*MyStruct)(nil))
+	for i, td := range testData {
+		t.Run("TestSchema_ValidateAndInit_"+strconv.Itoa(i), func(t *testing.T) {
+			err := schema.ValidateAndInit(td.m, td.i)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if s, ok := td.i.(*MyStruct); ok && td.noErrorFlag && (s.A != 1 || s.B != "value") {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	type MyStruct struct {
+		A int `datakey:"a" validate:"required,int"`
+	}
+	type Other struct {
+		A int `datakey:"a"`
+	}
+
+	testData := []struct {
+		m           map[string]string
+		i           interface{}
+		noErrorFlag bool
+	}{
+		{map[string]string{"a": "1"}, &MyStruct{}, true},
+		{map[string]string{"a": "x"}, &MyStruct{}, false},
+		{map[string]string{"a": "1"}, &Other{}, false},
+	}
+
+	schema := New().MustCompile(&MyStruct{})
+	for i, td := range testData {
+		t.Run("TestSchema_Validate_"+strconv.Itoa(i), func(t *testing.T) {
+			err := schema.Validate(td.m, td.i)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+
+			err = schema.Check(td.m)
+			if _, isOther := td.i.(*Other); !isOther && td.noErrorFlag != (err == nil) {
+				t.Error()
+			}
+
+			err = schema.Init(td.m, td.i)
+			if s, ok := td.i.(*MyStruct); ok && err == nil && s.A != 1 {
+				t.Error()
+			} else if _, isOther := td.i.(*Other); isOther && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
@@ -0,0 +1,537 @@
+//This file contains the validation of the structs that are already populated (e.g. decoded from JSON or read from a
+//database), based on the same "validate" tags as the map data:
+//
+//		order := Order{}
+//		_ = json.Unmarshal(body, &order)
+//		err := v.ValidateStruct(&order)
+//
+//The rules are applied on the typed values of the fields through their value based implementations (the builtin
+//struct rules below and the rules registered with RegisterValueRule): numbers are compared by value, times
+//chronologically and strings, slices, arrays and maps by their length
+//The rules that only have a map based implementation (e.g. "email", "regex", the rules registered with RegisterRule)
+//are applied on the textual form of the value (see formatValue)
+
+package validator
+
+import (
+	"encoding"
+	"fmt"
+	"github.com/pkg/errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+//The information about a field of a populated struct that is needed to apply its rules
+//"key" and "path" are the map key and the path of the field, "value" its value and "absent" is true if the value
+//counts as an absent map key (see isAbsent); "prefix" is the key prefix of the struct that contains the field and
+//"data" the textual form of the fields of the struct that are present, by map key (see structData)
+type valueContext struct {
+	key    string
+	path   string
+	prefix string
+	value  reflect.Value
+	absent bool
+	lookup FieldLookup
+	data   map[string]string
+}
+
+//Validates a populated struct based on the rules defined on the struct's tags
+//The i parameter is a struct or a pointer to a struct; the fields linked to a map key via the "datakey" tag are
+//validated like the map values of ValidateAndInit, followed by the Validate and ValidateWith methods of the struct
+//A field holding the zero value of its type (e.g. 0, "", a nil pointer, an empty slice) counts as an absent key for
+//the "required" and the conditional rules (e.g. "required_if"); the range rules (e.g. "min", "between", "maxlen") are
+//applied on the zero values too, except the nil pointers, while the other rules skip them
+//The unexported fields are skipped, since their values cannot be read, together with the fields of the unexported sub
+//structs that are not embedded
+func (v *Validator) ValidateStruct(i interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(i))
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("please provide a struct or a pointer to the struct")
+	}
+	if !v.isInit {
+		return fmt.Errorf("validator not initialized: call New()")
+	}
+
+	//The struct is copied so that the Validate methods with a pointer receiver can be called
+	t := reflect.New(value.Type()).Elem()
+	t.Set(value)
+
+	err := v.checkStructRules(t)
+	if err != nil {
+		return errors.Wrap(err, "error validation struct values based on rules")
+	}
+
+	err = v.callHooks(t)
+	if err != nil {
+		return errors.Wrap(err, "error validation struct")
+	}
+
+	return nil
+}
+
+//Applies the rules defined on the struct's tags on the values of the populated struct "t"
+//When the Validator collects all the errors, the failures are returned together as ValidationErrors
+func (v *Validator) checkStructRules(t reflect.Value) error {
+	position := structPosition{parents: []reflect.Type{t.Type()}}
+	values := make(map[string]reflect.Value)
+	_ = v.walkValues(nil, t, position, func(fp fieldPlan, value reflect.Value, position structPosition) error {
+		if mapKey := position.key(fp.field); mapKey != "" && fp.field.PkgPath == "" {
+			values[mapKey] = value
+		}
+		return nil
+	})
+	data := structData(values)
+
+	failures := ValidationErrors{}
+	err := v.walkValues(nil, t, position, func(fp fieldPlan, value reflect.Value, position structPosition) error {
+		mapKey := position.key(fp.field)
+		if mapKey == "" || fp.field.PkgPath != "" {
+			return nil
+		}
+		vc := valueContext{key: mapKey, path: fieldPath(position.path, fp.field.Name), prefix: position.prefix,
+			value: value, absent: isAbsent(value), data: data}
+		vc.lookup = func(key string) (reflect.Value, bool) {
+			value, ok := values[resolveKey(position.prefix, key)]
+			return value, ok
+		}
+
+		if fp.hasRules {
+			err := checkRuleTag(fp, vc.path)
+			if err != nil {
+				return err
+			}
+			err = v.applyStructRules(fp.rules, vc, &failures)
+			if err != nil {
+				return err
+			}
+			if fp.isDive && !vc.absent {
+				err = v.applyStructElementRules(fp, vc, &failures)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if !vc.absent {
+			return v.applyStructEnum(vc, &failures)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+//Applies a list of rules on a value of a populated struct
+//The value based implementation of a rule is used if there is one, otherwise the map based one is called with the
+//textual form of the value (and of the other fields of the struct for the field rules, e.g. the conditional rules);
+//only the presence rules and the range rules are applied on the absent values (the range rules unless the value is a
+//nil pointer)
+func (v *Validator) applyStructRules(rules []compiledRule, vc valueContext, failures *ValidationErrors) error {
+	value := indirectValue(vc.value)
+	for _, rule := range rules {
+		if !rule.isDefined() && rule.structRule == nil {
+			return fmt.Errorf("validation rule '%s' has no implementation. "+
+				"please use 'RegisterRule' to provide one", rule.name)
+		}
+		isPresence := isPresenceRule(rule.name)
+		if vc.absent && !isPresence && !(isRangeRule(rule.name) && !isNilValue(vc.value)) {
+			continue
+		}
+
+		var err error
+		formatted, isFormatted := formatValue(value)
+		if vc.absent && isPresence {
+			formatted = ""
+		}
+		switch {
+		case rule.structRule != nil && isPresence:
+			//The presence rules see the pointers themselves, since a pointer to a zero value counts as present
+			err = rule.structRule(vc.value, vc.lookup, rule.params...)
+		case rule.structRule != nil:
+			err = rule.structRule(value, vc.lookup, rule.params...)
+		case rule.rule != nil && isFormatted:
+			err = rule.rule(vc.key, map[string]string{vc.key: formatted}, rule.params...)
+		case rule.fieldRule != nil:
+			//The absent values are absent keys, like the absent fields inside "data"
+			fc := fieldContext{key: vc.key, m: map[string]string{}, data: vc.data, prefix: vc.prefix}
+			if !vc.absent {
+				fc.m[vc.key] = formatted
+			}
+			err = rule.fieldRule(fc, rule.params...)
+		default:
+			return fmt.Errorf("validation rule '%s' cannot be applied on the values of type '%s', field '%s'. "+
+				"please use 'RegisterValueRule' to provide a value based implementation", rule.name,
+				vc.value.Type(), vc.path)
+		}
+		if err != nil {
+			if err := ruleConfigError(err, rule.name, vc.path); err != nil {
+				return err
+			}
+			fieldErr := toFieldError(err, vc.path, vc.key, rule.name, formatted, rule.params)
+			if err := v.reportFailure(fieldErr, failures); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Applies the rules following the "dive" modifier on each element of a slice or array field and on each entry of a
+//map field (the rules between "keys" and "endkeys" on the entry keys, the remaining ones on the entry values)
+//The elements count as present, unless they are nil pointers; the failures have the index of the element or the
+//entry key in their field path (e.g. "Tags[1]", "Labels[env]")
+func (v *Validator) applyStructElementRules(fp fieldPlan, vc valueContext, failures *ValidationErrors) error {
+	value := indirectValue(vc.value)
+	if value.Kind() == reflect.Map {
+		for _, key := range sortedMapKeys(value) {
+			name, _ := formatValue(key)
+			entryContext := valueContext{key: vc.key + "." + name, path: fmt.Sprintf("%s[%s]", vc.path, name),
+				prefix: vc.prefix, lookup: vc.lookup, data: vc.data}
+			keyContext, entryValueContext := entryContext, entryContext
+			keyContext.value = key
+			entryValueContext.value = value.MapIndex(key)
+			entryValueContext.absent = isNilValue(entryValueContext.value)
+			err := v.applyStructRules(fp.keyRules, keyContext, failures)
+			if err != nil {
+				return err
+			}
+			err = v.applyStructRules(fp.valueRules, entryValueContext, failures)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	for index := 0; index < value.Len(); index++ {
+		elementContext := valueContext{key: vc.key, path: fmt.Sprintf("%s[%d]", vc.path, index), prefix: vc.prefix,
+			value: value.Index(index), absent: isNilValue(value.Index(index)), lookup: vc.lookup, data: vc.data}
+		err := v.applyStructRules(fp.elementRules, elementContext, failures)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Checks if a value of a populated struct (or each one of its elements for slices and arrays, or each one of its entry
+//values for maps) is one of the values registered for its type via RegisterEnum
+func (v *Validator) applyStructEnum(vc valueContext, failures *ValidationErrors) error {
+	value := indirectValue(vc.value)
+	if values, ok := v.lookupEnum(value.Type().String()); ok {
+		return v.applyStructEnumValues(values, vc, value, failures)
+	}
+	if value.Kind() != reflect.Map && !isSequence(value.Type()) {
+		return nil
+	}
+	values, ok := v.lookupEnum(indirectType(value.Type().Elem()).String())
+	if !ok {
+		return nil
+	}
+
+	if value.Kind() == reflect.Map {
+		for _, key := range sortedMapKeys(value) {
+			name, _ := formatValue(key)
+			entryContext := valueContext{key: vc.key + "." + name, path: fmt.Sprintf("%s[%s]", vc.path, name)}
+			err := v.applyStructEnumValues(values, entryContext, indirectValue(value.MapIndex(key)), failures)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for index := 0; index < value.Len(); index++ {
+		elementContext := valueContext{key: vc.key, path: fmt.Sprintf("%s[%d]", vc.path, index)}
+		err := v.applyStructEnumValues(values, elementContext, indirectValue(value.Index(index)), failures)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Checks if the textual form of a value is one of the given enum values
+func (v *Validator) applyStructEnumValues(values []string, vc valueContext, value reflect.Value,
+	failures *ValidationErrors) error {
+	formatted, ok := formatValue(value)
+	if !ok {
+		return nil
+	}
+	err := checkEnum(vc.key, map[string]string{vc.key: formatted}, values...)
+	if err != nil {
+		fieldErr := toFieldError(err, vc.path, vc.key, ruleEnum, formatted, values)
+		return v.reportFailure(fieldErr, failures)
+	}
+	return nil
+}
+
+//Validates if the value is not absent (see isAbsent)
+func checkValueRequired(value reflect.Value, lookup FieldLookup, params ...string) error {
+	if isAbsent(value) {
+		return &FieldError{Rule: ruleRequired, Params: params, Err: fmt.Errorf("required value is not present")}
+	}
+	return nil
+}
+
+//Validates if the value is an integer: a value of an integer type or a string that can be converted to an integer
+func checkValueInt(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueType(value, checkInt, params, isSigned(value.Kind()) || isUnsigned(value.Kind()))
+}
+
+//Validates if the value is an unsigned integer: a value of an unsigned integer type, a non negative value of a signed
+//integer type or a string that can be converted to an unsigned integer
+func checkValueUnsigned(value reflect.Value, lookup FieldLookup, params ...string) error {
+	accepted := isUnsigned(value.Kind()) || (isSigned(value.Kind()) && value.Int() >= 0)
+	return checkValueType(value, checkUnsigned, params, accepted)
+}
+
+//Validates if the value is a time: a time.Time value or a string in the RFC3339 format
+func checkValueTime(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueType(value, checkTime, params, value.Type() == reflect.TypeOf(time.Time{}))
+}
+
+//Validates if the value is a boolean: a value of a boolean type or one of the strings "true" and "false"
+func checkValueBool(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueType(value, checkBool, params, value.Kind() == reflect.Bool)
+}
+
+//Common implementation of the type rules ("int", "unsigned", "time", "bool")
+//String values are checked with the map based implementation "check", any other value is valid if "accepted" is true
+func checkValueType(value reflect.Value, check func(mapKey string, m map[string]string, params ...string) error,
+	params []string, accepted bool) error {
+	if value.Kind() == reflect.String {
+		return check("", map[string]string{"": value.String()}, params...)
+	}
+	if !accepted {
+		return &FieldError{Params: params, Err: fmt.Errorf("values of type '%s' do not match the rule", value.Type())}
+	}
+	return nil
+}
+
+//Validates if the value is greater than or equal to the first param
+//Numbers are compared by value, strings by their number of characters and slices, arrays and maps by their number of
+//elements
+func checkValueMin(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueBounds(value, ruleMin, params, measureNumber, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure >= bounds[0]
+	})
+}
+
+//Validates if the value is less than or equal to the first param
+//Numbers are compared by value, strings by their number of characters and slices, arrays and maps by their number of
+//elements
+func checkValueMax(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueBounds(value, ruleMax, params, measureNumber, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure <= bounds[0]
+	})
+}
+
+//Validates if the value is between the two params (inclusive)
+//Numbers are compared by value, strings by their number of characters and slices, arrays and maps by their number of
+//elements
+func checkValueBetween(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueBounds(value, ruleBetween, params, measureNumber, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 2 && measure >= bounds[0] && measure <= bounds[1]
+	})
+}
+
+//Validates if the length of the value is equal to the first param
+//The length of a string is its number of characters and the length of a slice, array or map its number of elements
+func checkValueLen(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueBounds(value, ruleLen, params, measureSize, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure == bounds[0]
+	})
+}
+
+//Validates if the length of the value is greater than or equal to the first param
+//The length of a string is its number of characters and the length of a slice, array or map its number of elements
+func checkValueMinLen(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueBounds(value, ruleMinLen, params, measureSize, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure >= bounds[0]
+	})
+}
+
+//Validates if the length of the value is less than or equal to the first param
+//The length of a string is its number of characters and the length of a slice, array or map its number of elements
+func checkValueMaxLen(value reflect.Value, lookup FieldLookup, params ...string) error {
+	return checkValueBounds(value, ruleMaxLen, params, measureSize, func(measure float64, bounds []float64) bool {
+		return len(bounds) == 1 && measure <= bounds[0]
+	})
+}
+
+//Common implementation of the value based range rules
+//Parses the params as numbers (or durations for the time.Duration values, see parseBounds), measures the value using
+//"measure" and checks the result with "accept"
+func checkValueBounds(value reflect.Value, rule string, params []string, measure func(value reflect.Value) (float64,
+	error), accept func(measure float64, bounds []float64) bool) error {
+	bounds, err := parseBounds(params, value.Type() == durationType)
+	if err != nil {
+		return &configError{err: err}
+	}
+
+	measured, err := measure(value)
+	if err != nil {
+		return &FieldError{Rule: rule, Params: params, Err: err}
+	}
+	if !accept(measured, bounds) {
+		return &FieldError{Rule: rule, Params: params,
+			Err: fmt.Errorf("measured value %v does not match constraint '%s=%s'", measured, rule,
+				strings.Join(params, "|"))}
+	}
+
+	return nil
+}
+
+//Measures a value: numbers are measured by their value, any other value by its length (see measureSize)
+func measureNumber(value reflect.Value) (float64, error) {
+	if isNumber(value.Kind()) {
+		return toFloat(value), nil
+	}
+	return measureSize(value)
+}
+
+//Measures the length of a value: the number of characters of a string or the number of elements of a slice, array or
+//map; any other value is measured by the number of characters of its textual form (see formatValue)
+func measureSize(value reflect.Value) (float64, error) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), nil
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), nil
+	}
+	if formatted, ok := formatValue(value); ok {
+		return float64(utf8.RuneCountInString(formatted)), nil
+	}
+	return 0, fmt.Errorf("values of type '%s' cannot be measured", value.Type())
+}
+
+//Returns the textual form of the fields of a populated struct that are present (see isAbsent), by map key, so that
+//the map based field rules can reference them like the keys of the map data; the present values that have no textual
+//form (e.g. a struct, a slice) are empty strings
+func structData(values map[string]reflect.Value) map[string]string {
+	data := make(map[string]string, len(values))
+	for key, value := range values {
+		if !isAbsent(value) {
+			data[key], _ = formatValue(value)
+		}
+	}
+
+	return data
+}
+
+//Checks if a rule is applied on the absent values of a populated struct: "required" and the conditional rules
+func isPresenceRule(name string) bool {
+	switch name {
+	case ruleRequired, ruleRequiredIf, ruleRequiredUnless, ruleRequiredWith, ruleRequiredWithAll, ruleRequiredWithout,
+		ruleExcludedIf, ruleExcludedWith:
+		return true
+	default:
+		return false
+	}
+}
+
+//Checks if a rule is applied on the zero values of a populated struct, unlike the other rules which treat them as
+//absent keys: the range rules, so that e.g. a zero "int" field does not match "min=18"
+func isRangeRule(name string) bool {
+	switch name {
+	case ruleMin, ruleMax, ruleBetween, ruleLen, ruleMinLen, ruleMaxLen:
+		return true
+	default:
+		return false
+	}
+}
+
+//Checks if a value of a populated struct counts as an absent map key: nil pointers and interfaces, empty slices and
+//maps and the zero values of the other types
+func isAbsent(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return value.IsNil()
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+//Checks if a value is a nil pointer or interface
+func isNilValue(value reflect.Value) bool {
+	return (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && value.IsNil()
+}
+
+//Returns the value pointed to by a value, following the pointers and the interfaces until a nil one is found
+func indirectValue(value reflect.Value) reflect.Value {
+	for (value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface) && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+//Returns the type pointed to by a type, following the pointers
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+//Returns the textual form of a value, as it would be provided inside the map data: strings as they are, the values
+//implementing encoding.TextMarshaler or fmt.Stringer through their methods (e.g. "2019-08-21T09:00:00Z" for a
+//time.Time, "1m30s" for a time.Duration) and the numbers and booleans in their usual format
+//Returns false if the value has no textual form (e.g. a struct, a slice) or is a nil pointer
+func formatValue(value reflect.Value) (string, bool) {
+	value = indirectValue(value)
+	if !value.IsValid() || isNilValue(value) {
+		return "", false
+	}
+	if value.Kind() == reflect.String {
+		return value.String(), true
+	}
+	if value.CanInterface() {
+		switch formatter := value.Interface().(type) {
+		case encoding.TextMarshaler:
+			if text, err := formatter.MarshalText(); err == nil {
+				return string(text), true
+			}
+		case fmt.Stringer:
+			return formatter.String(), true
+		}
+	}
+
+	switch {
+	case value.Kind() == reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true
+	case isSigned(value.Kind()):
+		return strconv.FormatInt(value.Int(), 10), true
+	case isUnsigned(value.Kind()):
+		return strconv.FormatUint(value.Uint(), 10), true
+	case value.Kind() == reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 32), true
+	case value.Kind() == reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'f', -1, 64), true
+	default:
+		return "", false
+	}
+}
+
+//Returns the keys of a map value, sorted by their textual form, so that the entries are validated in a stable order
+func sortedMapKeys(value reflect.Value) []reflect.Value {
+	keys := value.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		a, _ := formatValue(keys[i])
+		b, _ := formatValue(keys[j])
+		return a < b
+	})
+
+	return keys
+}
@@ -0,0 +1,468 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+type structsPeriod struct {
+	Start time.Time `datakey:"start" validate:"required"`
+	End   time.Time `datakey:"end" validate:"required,gtfield=start"`
+}
+
+func (p *structsPeriod) Validate() error {
+	if p.End.Sub(p.Start) > 24*time.Hour {
+		return fmt.Errorf("period is longer than a day")
+	}
+	return nil
+}
+
+func TestStructs_ValidateStruct(t *testing.T) {
+	type Status string
+	type Address struct {
+		Country string `datakey:"country" validate:"required,oneof=RO|US"`
+		State   string `datakey:"state" validate:"required_if=country|US"`
+	}
+	type Item struct {
+		SKU string `datakey:"sku" validate:"required,pattern=sku"`
+		Qty int    `datakey:"qty" validate:"between=1|100"`
+	}
+	type MyStruct struct {
+		ID      int               `datakey:"id" validate:"required,min=1"`
+		Email   string            `datakey:"email" validate:"email"`
+		Count   *int              `datakey:"count" validate:"max=10"`
+		Tags    []string          `datakey:"tags" validate:"maxlen=3,dive,minlen=2"`
+		Labels  map[string]string `datakey:"label" validate:"dive,keys,minlen=2,endkeys,required"`
+		Status  Status            `datakey:"status"`
+		Timeout time.Duration     `datakey:"timeout" validate:"oneof=1m0s|2m0s"`
+		Billing *Address          `datakey:"billing"`
+		Items   []Item            `datakey:"items" validate:"maxlen=2"`
+		Period  structsPeriod     `datakey:"period"`
+		private string            `datakey:"private" validate:"required"`
+	}
+
+	zero := 0
+	eleven := 11
+	start := time.Date(2019, 8, 21, 9, 0, 0, 0, time.UTC)
+	valid := func() MyStruct {
+		return MyStruct{ID: 1, Email: "john@example.com", Count: &zero, Tags: []string{"ab", "cd"},
+			Labels: map[string]string{"env": "prod"}, Status: "active", Timeout: time.Minute,
+			Billing: &Address{Country: "US", State: "CA"}, Items: []Item{{SKU: "ABC-1234", Qty: 1}},
+			Period: structsPeriod{Start: start, End: start.Add(time.Hour)}}
+	}
+
+	testData := []struct {
+		change      func(s *MyStruct)
+		noErrorFlag bool
+	}{
+		{func(s *MyStruct) {}, true},
+		{func(s *MyStruct) { s.Email, s.Count, s.Tags, s.Labels = "", nil, nil, nil }, true},
+		{func(s *MyStruct) { s.Status, s.Timeout = "", 0 }, true},
+		{func(s *MyStruct) { s.Billing, s.Items = nil, nil }, true},
+		{func(s *MyStruct) { s.ID = 0 }, false},
+		{func(s *MyStruct) { s.ID = -1 }, false},
+		{func(s *MyStruct) { s.Email = "john" }, false},
+		{func(s *MyStruct) { s.Count = &eleven }, false},
+		{func(s *MyStruct) { s.Tags = []string{"ab", "cd", "ef", "gh"} }, false},
+		{func(s *MyStruct) { s.Tags = []string{"ab", ""} }, false},
+		{func(s *MyStruct) { s.Labels = map[string]string{"e": "prod"} }, false},
+		{func(s *MyStruct) { s.Labels = map[string]string{"env": ""} }, false},
+		{func(s *MyStruct) { s.Status = "deleted" }, false},
+		{func(s *MyStruct) { s.Timeout = time.Second }, false},
+		{func(s *MyStruct) { s.Billing.State = "" }, false},
+		{func(s *MyStruct) { s.Billing.Country, s.Billing.State = "RO", "" }, true},
+		{func(s *MyStruct) { s.Billing.Country = "" }, false},
+		{func(s *MyStruct) { s.Items = append(s.Items, Item{SKU: "abc"}) }, false},
+		{func(s *MyStruct) { s.Items[0].Qty = 101 }, false},
+		{func(s *MyStruct) { s.Items[0].Qty = 0 }, false},
+		{func(s *MyStruct) { s.Period.End = s.Period.Start }, false},
+		{func(s *MyStruct) { s.Period.End = s.Period.Start.Add(48 * time.Hour) }, false},
+	}
+
+	v := New()
+	_ = v.RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$")
+	_ = v.RegisterEnum(reflect.TypeOf(Status("")).String(), "active", "inactive")
+	for i, td := range testData {
+		t.Run("TestStructs_ValidateStruct_"+strconv.Itoa(i), func(t *testing.T) {
+			s := valid()
+			td.change(&s)
+			err := v.ValidateStruct(&s)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if err := v.ValidateStruct(s); (err == nil) != td.noErrorFlag {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestStructs_ValidateStruct2(t *testing.T) {
+	type Address struct {
+		Country string `datakey:"country"`
+		State   string `datakey:"state" validate:"required_if=country|US"`
+	}
+	type MyStruct struct {
+		ID      int      `datakey:"id" validate:"required"`
+		Tags    []string `datakey:"tags" validate:"dive,minlen=2"`
+		Billing Address  `datakey:"billing"`
+	}
+
+	v := New()
+	v.SetCollectAllErrors(true)
+	err := v.ValidateStruct(MyStruct{Tags: []string{"ab", "c"}, Billing: Address{Country: "US"}})
+	var failures ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 3 {
+		t.Fatal(err)
+	}
+	expected := []FieldError{
+		{Field: "ID", Key: "id", Rule: ruleRequired},
+		{Field: "Tags[1]", Key: "tags", Rule: ruleMinLen, Value: "c"},
+		{Field: "Billing.State", Key: "billing.state", Rule: ruleRequiredIf, Trigger: "billing.country",
+			Err: fmt.Errorf("key 'billing.state' is required when key 'billing.country' is 'US'")},
+	}
+	for index, failure := range failures {
+		if failure.Field != expected[index].Field || failure.Key != expected[index].Key ||
+			failure.Rule != expected[index].Rule || failure.Trigger != expected[index].Trigger ||
+			failure.Value != expected[index].Value ||
+			(expected[index].Err != nil && failure.Err.Error() != expected[index].Err.Error()) {
+			t.Error(failure)
+		}
+	}
+
+	//The conditional rules fail with the same message as for the map data
+	err = v.ValidateAndInit(map[string]string{"id": "1", "billing.country": "US"}, &MyStruct{})
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].Err.Error() != expected[2].Err.Error() {
+		t.Error(err)
+	}
+}
+
+func TestStructs_ValidateStruct3(t *testing.T) {
+	type Inner struct {
+		A string `datakey:"a" validate:"unknown"`
+	}
+	type Other struct {
+		A []int `datakey:"a" validate:"email"`
+	}
+
+	v := New()
+	if err := v.ValidateStruct(Inner{}); err == nil {
+		t.Error()
+	}
+	if err := v.ValidateStruct(Other{A: []int{1}}); err == nil {
+		t.Error()
+	}
+	if err := v.ValidateStruct(1); err == nil {
+		t.Error()
+	}
+	if err := v.ValidateStruct(Other{}); err != nil {
+		t.Error(err)
+	}
+
+	//The misconfigured rules are reported as plain errors, not as failures
+	type Bounds struct {
+		A int    `datakey:"a" validate:"min=x"`
+		B string `datakey:"b" validate:"pattern=unknown"`
+	}
+	v.SetCollectAllErrors(true)
+	var failures ValidationErrors
+	if err := v.ValidateStruct(Bounds{}); err == nil || errors.As(err, &failures) {
+		t.Error(err)
+	}
+	if err := v.ValidateStruct(Bounds{A: 1, B: "x"}); err == nil || errors.As(err, &failures) {
+		t.Error(err)
+	}
+	v.SetCollectAllErrors(false)
+
+	_ = v.RegisterValueRule("unknown", func(value reflect.Value, lookup FieldLookup, params ...string) error {
+		if value.String() != "x" {
+			return fmt.Errorf("value is not x")
+		}
+		return nil
+	})
+	if err := v.ValidateStruct(Inner{A: "x"}); err != nil {
+		t.Error(err)
+	}
+	if err := v.ValidateStruct(Inner{A: "y"}); err == nil {
+		t.Error()
+	}
+
+	//The rules registered with RegisterRule replace the builtin ones for ValidateStruct too
+	type Required struct {
+		A string `datakey:"a" validate:"required"`
+		B int    `datakey:"b" validate:"min=5"`
+	}
+	if err := v.ValidateStruct(Required{B: 5}); err == nil {
+		t.Error()
+	}
+	_ = v.RegisterRule(ruleRequired, func(mapKey string, m map[string]string, params ...string) error {
+		return nil
+	})
+	_ = v.RegisterRule(ruleMin, func(mapKey string, m map[string]string, params ...string) error {
+		if m[mapKey] != "3" {
+			return fmt.Errorf("value is not 3")
+		}
+		return nil
+	})
+	if err := v.ValidateStruct(Required{B: 3}); err != nil {
+		t.Error(err)
+	}
+	if err := v.ValidateStruct(Required{B: 5}); err == nil {
+		t.Error()
+	}
+}
+
+func TestStructs_ValidateStruct4(t *testing.T) {
+	type MyStruct struct {
+		Age   int      `datakey:"age" validate:"min=18"`
+		Temp  int      `datakey:"temp" validate:"between=-5|-1"`
+		Name  string   `datakey:"name" validate:"minlen=2"`
+		Tags  []string `datakey:"tags" validate:"len=1"`
+		Count *int     `datakey:"count" validate:"min=1"`
+		Email string   `datakey:"email" validate:"email"`
+	}
+
+	zero := 0
+	testData := []struct {
+		s           MyStruct
+		noErrorFlag bool
+		rules       []string
+	}{
+		{MyStruct{Age: 18, Temp: -1, Name: "ab", Tags: []string{"a"}}, true, nil},
+		{MyStruct{Temp: -1, Name: "ab", Tags: []string{"a"}}, false, []string{ruleMin}},
+		{MyStruct{Age: 18, Name: "ab", Tags: []string{"a"}}, false, []string{ruleBetween}},
+		{MyStruct{Age: 18, Temp: -1}, false, []string{ruleMinLen, ruleLen}},
+		{MyStruct{Age: 18, Temp: -1, Name: "ab", Tags: []string{"a"}, Count: &zero}, false, []string{ruleMin}},
+		{MyStruct{}, false, []string{ruleMin, ruleBetween, ruleMinLen, ruleLen}},
+	}
+
+	v := New()
+	v.SetCollectAllErrors(true)
+	for i, td := range testData {
+		t.Run("TestStructs_ValidateStruct4_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.ValidateStruct(td.s)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+
+			var failures ValidationErrors
+			if err != nil && (!errors.As(err, &failures) || len(failures) != len(td.rules)) {
+				t.Fatal(err)
+			}
+			for index, failure := range failures {
+				if failure.Rule != td.rules[index] {
+					t.Error(failure)
+				}
+			}
+		})
+	}
+}
+
+func TestStructs_ValidateStruct5(t *testing.T) {
+	type period struct {
+		Start time.Time `datakey:"start"`
+		End   time.Time `datakey:"end" validate:"required,gtfield=start"`
+	}
+	type MyStruct struct {
+		period
+		ID    int    `datakey:"id" validate:"required"`
+		inner period `datakey:"inner"`
+	}
+
+	start := time.Date(2019, 8, 21, 9, 0, 0, 0, time.UTC)
+	testData := []struct {
+		s           MyStruct
+		noErrorFlag bool
+	}{
+		{MyStruct{ID: 1, period: period{Start: start, End: start.Add(time.Hour)}}, true},
+		{MyStruct{ID: 1, period: period{Start: start, End: start.Add(time.Hour)}, inner: period{Start: start}}, true},
+		{MyStruct{ID: 1, period: period{Start: start, End: start}}, false},
+		{MyStruct{ID: 1}, false},
+	}
+
+	v := New()
+	for i, td := range testData {
+		t.Run("TestStructs_ValidateStruct5_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.ValidateStruct(td.s)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestStructs_ValidateStruct6(t *testing.T) {
+	type Contact struct {
+		Country string `datakey:"country"`
+		Email   string `datakey:"email"`
+		Phone   string `datakey:"phone"`
+	}
+	contact := Contact{Country: "US"}
+
+	testData := []struct {
+		s           interface{}
+		noErrorFlag bool
+	}{
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_if=country|US"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_if=country|RO"`
+		}{Contact: contact}, true},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_if=country"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_unless=country|RO"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_with=email|country"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_with_all=email|country"`
+		}{Contact: contact}, true},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_without=email"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_without=email"`
+		}{Contact: contact, V: "x"}, true},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"excluded_if=country|US"`
+		}{Contact: contact, V: "x"}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"excluded_with=phone"`
+		}{Contact: contact, V: "x"}, true},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_if=country US"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V string `datakey:"v" validate:"required_with=phone country"`
+		}{Contact: contact}, false},
+		{struct {
+			Contact
+			V []int `datakey:"v" validate:"excluded_with=country"`
+		}{Contact: contact, V: []int{1}}, false},
+	}
+
+	v := New()
+	for i, td := range testData {
+		t.Run("TestStructs_ValidateStruct6_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.ValidateStruct(td.s)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestStructs_checkValueRules(t *testing.T) {
+	lookup := func(key string) (reflect.Value, bool) {
+		return reflect.Value{}, false
+	}
+
+	testData := []struct {
+		rule        func(value reflect.Value, lookup FieldLookup, params ...string) error
+		value       interface{}
+		params      []string
+		noErrorFlag bool
+	}{
+		{checkValueRequired, 0, nil, false},
+		{checkValueRequired, []int{}, nil, false},
+		{checkValueRequired, time.Time{}, nil, false},
+		{checkValueRequired, 1, nil, true},
+		{checkValueInt, 1, nil, true},
+		{checkValueInt, "1", nil, true},
+		{checkValueInt, "x", nil, false},
+		{checkValueInt, 1.5, nil, false},
+		{checkValueUnsigned, -1, nil, false},
+		{checkValueUnsigned, uint(1), nil, true},
+		{checkValueTime, time.Now(), nil, true},
+		{checkValueTime, "2019-08-21T09:00:00Z", nil, true},
+		{checkValueTime, 1, nil, false},
+		{checkValueBool, true, nil, true},
+		{checkValueBool, "yes", nil, false},
+		{checkValueMin, 5, []string{"5"}, true},
+		{checkValueMin, 4.9, []string{"5"}, false},
+		{checkValueMin, "abcd", []string{"5"}, false},
+		{checkValueMin, 5, []string{"x"}, false},
+		{checkValueMax, uint8(200), []string{"100"}, false},
+		{checkValueBetween, 50, []string{"1", "100"}, true},
+		{checkValueBetween, 50, []string{"1"}, false},
+		{checkValueMin, time.Minute, []string{"30s"}, true},
+		{checkValueBetween, time.Second, []string{"1m", "2m"}, false},
+		{checkValueLen, []int{1, 2}, []string{"2"}, true},
+		{checkValueLen, "żółw", []string{"4"}, true},
+		{checkValueMinLen, map[string]int{"a": 1}, []string{"2"}, false},
+		{checkValueMaxLen, 12345, []string{"4"}, false},
+		{checkValueMaxLen, struct{}{}, []string{"4"}, false},
+	}
+
+	for i, td := range testData {
+		t.Run("TestStructs_checkValueRules_"+strconv.Itoa(i), func(t *testing.T) {
+			err := td.rule(reflect.ValueOf(td.value), lookup, td.params...)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestStructs_formatValue(t *testing.T) {
+	one := 1
+	var nilPointer *int
+
+	testData := []struct {
+		value    interface{}
+		expected string
+		ok       bool
+	}{
+		{"value", "value", true},
+		{-5, "-5", true},
+		{uint16(5), "5", true},
+		{1.5, "1.5", true},
+		{float32(0.1), "0.1", true},
+		{true, "true", true},
+		{&one, "1", true},
+		{time.Date(2019, 8, 21, 9, 0, 0, 0, time.UTC), "2019-08-21T09:00:00Z", true},
+		{90 * time.Second, "1m30s", true},
+		{nilPointer, "", false},
+		{[]int{1}, "", false},
+		{struct{}{}, "", false},
+	}
+
+	for i, td := range testData {
+		t.Run("TestStructs_formatValue_"+strconv.Itoa(i), func(t *testing.T) {
+			formatted, ok := formatValue(reflect.ValueOf(td.value))
+			if formatted != td.expected || ok != td.ok {
+				t.Error(formatted)
+			}
+		})
+	}
+}
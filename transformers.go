@@ -0,0 +1,129 @@
+//This file contains the builtin transformers and the logic that applies the "transform" tag of the struct fields
+//
+//Transformers normalize the raw map values before the validation and the conversion, in the order of the tag
+//(e.g. `transform:"trim,lower"` turns " Yes " into "yes"); the values of slice and array fields are transformed
+//element by element and the ones of map fields entry by entry
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//Removes the leading and trailing white space of a value
+//If a param is provided, it is the set of characters to remove instead (e.g. `transform:"trim=/"`)
+func transformToTrimmed(value string, params ...string) (string, error) {
+	if len(params) > 0 {
+		return strings.Trim(value, params[0]), nil
+	}
+
+	return strings.TrimSpace(value), nil
+}
+
+//Turns a value to lower case
+func transformToLower(value string, params ...string) (string, error) {
+	return strings.ToLower(value), nil
+}
+
+//Turns a value to upper case
+func transformToUpper(value string, params ...string) (string, error) {
+	return strings.ToUpper(value), nil
+}
+
+//Replaces each sequence of white space characters of a value with a single space, removing the leading and trailing
+//white space (e.g. "  John   Doe " -> "John Doe")
+func transformToCollapsed(value string, params ...string) (string, error) {
+	return strings.Join(strings.Fields(value), " "), nil
+}
+
+//Returns the map data with the values of the fields transformed based on their "transform" tag
+//The provided map is never modified: a copy is returned if at least one field has transformers; the elements provided
+//as separate values (see elementLists) are transformed in place
+//Returns the failures of the transformers; if the Validator does not collect all the errors, the first failure is
+//returned as error
+func (v *Validator) applyTransformers(m map[string]string, lists elementLists, t reflect.Type) (map[string]string,
+	ValidationErrors, error) {
+	result := m
+	isCopy := false
+	failures := ValidationErrors{}
+	err := v.walkFields(m, t, structPosition{parents: []reflect.Type{t}},
+		func(fp fieldPlan, position structPosition) error {
+			field := fp.field
+			mapKey := position.key(field)
+			if !fp.hasTransformers || mapKey == "" {
+				return nil
+			}
+			if fp.transformersErr != nil {
+				return fp.transformersErr
+			}
+
+			//Map fields have their values under the keys of their entries, whose values may be lists too
+			path := fieldPath(position.path, field.Name)
+			entries := []mapEntry{{key: mapKey}}
+			elementType := field.Type
+			if fp.isMap {
+				entries = findEntries(m, mapKey)
+				elementType = field.Type.Elem()
+			}
+
+			for _, entry := range entries {
+				key := entry.key
+				value, isPresent := m[key]
+				if !isPresent {
+					continue
+				}
+				entryPath := path
+				if entry.name != "" {
+					entryPath = fmt.Sprintf("%s[%s]", path, entry.name)
+				}
+				if !isCopy {
+					result, isCopy = copyMap(m), true
+				}
+				elements := []string{value}
+				if v.isElementList(elementType) {
+					elements = lists.split(key, value, fp.separator)
+				}
+				err := v.transformElements(elements, fp.transformers)
+				if fieldErr, ok := err.(*FieldError); ok {
+					fieldErr = toFieldError(fieldErr, entryPath, key, "", value, nil)
+					if err := v.reportFailure(fieldErr, &failures); err != nil {
+						return err
+					}
+					continue
+				} else if err != nil {
+					return err
+				}
+				result[key] = strings.Join(elements, fp.separator)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, failures, nil
+}
+
+//Applies a list of transformers, in order, on each element of a map value (the value itself for the fields that are
+//not slices or arrays); the elements are changed in place
+//Transformer failures are returned as FieldError values, while missing transformers as plain errors
+func (v *Validator) transformElements(elements []string, transformers []tagRule) error {
+	for _, transformer := range transformers {
+		transformerImpl, ok := v.lookupTransformer(transformer.name)
+		if !ok {
+			return fmt.Errorf("transformer '%s' has no implementation. "+
+				"please use 'RegisterTransformer' to provide one", transformer.name)
+		}
+		for index, element := range elements {
+			transformed, err := transformerImpl(element, transformer.params...)
+			if err != nil {
+				return toFieldError(err, "", "", transformer.name, element, transformer.params)
+			}
+			elements[index] = transformed
+		}
+	}
+
+	return nil
+}
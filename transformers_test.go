@@ -0,0 +1,98 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestTransformers_builtins(t *testing.T) {
+	testdata := []struct {
+		transformer func(value string, params ...string) (string, error)
+		in          string
+		params      []string
+		out         string
+	}{
+		{transformToTrimmed, " \t1 \n", nil, "1"},
+		{transformToTrimmed, "/a/b/", []string{"/"}, "a/b"},
+		{transformToLower, "YeS", nil, "yes"},
+		{transformToUpper, "ro", nil, "RO"},
+		{transformToCollapsed, "  John \t  Doe ", nil, "John Doe"},
+		{transformToCollapsed, "   ", nil, ""},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestTransformers_"+strconv.Itoa(i), func(t *testing.T) {
+			result, err := td.transformer(td.in, td.params...)
+			if err != nil || result != td.out {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestTransformers_applyTransformers(t *testing.T) {
+	type MyStruct struct {
+		A int               `datakey:"a" transform:"trim"`
+		B []string          `datakey:"b" transform:"trim,upper"`
+		C map[string]string `datakey:"c" transform:"lower"`
+		D string            `datakey:"d" datasep:";" transform:"collapse"`
+		E string            `datakey:"e" transform:"digits"`
+	}
+	testdata := []struct {
+		in          map[string]string
+		out         map[string]string
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{"x": " x "},
+			map[string]string{"x": " x "},
+			true,
+		},
+		{
+			map[string]string{"a": " 1 ", "b": " ro , md", "c.Env": "PROD", "d": " a  b ;c", "x": " x "},
+			map[string]string{"a": "1", "b": "RO,MD", "c.Env": "prod", "d": "a b ;c", "x": " x "},
+			true,
+		},
+		{
+			map[string]string{"e": "1.000"},
+			map[string]string{"e": "1000"},
+			true,
+		},
+		{
+			map[string]string{"e": "1.000x"},
+			nil,
+			false,
+		},
+	}
+
+	v := New()
+	_ = v.RegisterTransformer("digits", func(value string, params ...string) (string, error) {
+		value = strings.Replace(value, ".", "", -1)
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("not a number")
+		}
+		return value, nil
+	})
+	for i, td := range testdata {
+		t.Run("TestApplyTransformers_"+strconv.Itoa(i), func(t *testing.T) {
+			in := copyMap(td.in)
+			result, _, err := v.applyTransformers(in, nil, reflect.TypeOf(MyStruct{}))
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(result, td.out) ||
+				!reflect.DeepEqual(in, td.in)) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+
+	type Unknown struct {
+		A string `datakey:"a" transform:"nothing"`
+	}
+	if _, _, err := v.applyTransformers(map[string]string{"a": "1"}, nil, reflect.TypeOf(Unknown{})); err == nil {
+		t.Error()
+	}
+}
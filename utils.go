@@ -1,3 +1,474 @@
 //This file is used to define utility functions for the Validator
 
 package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	//characters with special meaning inside the "validate" tag
+	tagRuleSeparator  byte = ','
+	tagParamsStart    byte = '='
+	tagParamSeparator byte = '|'
+	tagEscape         byte = '\\'
+)
+
+type (
+	//A map key linked to a struct field, together with the path of the field (e.g. "Inner.C")
+	keyField struct {
+		key   string
+		path  string
+		field reflect.StructField
+	}
+
+	//An element of a slice of sub structs found in the map, with its index and the prefix of its keys
+	//Map key example: "items[0].sku" -> index: 0, prefix: "items[0]"
+	mapElement struct {
+		index  int
+		prefix string
+	}
+
+	//An entry of a map field found in the map, with its name and the map key that holds its value
+	//Map key example: "label.env" -> name: "env", key: "label.env"
+	mapEntry struct {
+		name string
+		key  string
+	}
+
+	//A single rule extracted from the "validate" tag, made of the rule name and its optional parameters
+	//Tag rule example: "between=1|10" -> name: "between", params: ["1", "10"]
+	tagRule struct {
+		name   string
+		params []string
+	}
+)
+
+//Parses the content of a "validate" tag into the list of rules it defines
+//Rules are separated by commas, the parameters of a rule follow an equal sign and are separated by pipes:
+//
+//		validate:"required,min=3,oneof=a|b|c"
+//
+//The special characters can be used inside the parameter values by escaping them with a backslash
+//(e.g. "\,", "\|", "\=", "\\"); a backslash followed by any other character is kept as it is, so the
+//parameters of rules like "regex" do not need double escaping
+//Empty rules (e.g. "required,,int" or an empty tag) are ignored
+func parseRules(tag string) ([]tagRule, error) {
+	rules := make([]tagRule, 0)
+	current := tagRule{}
+	token := strings.Builder{}
+	hasParams := false
+
+	//Stores the current token either as the rule name or as one of its parameters
+	endToken := func() {
+		if hasParams {
+			current.params = append(current.params, token.String())
+		} else {
+			current.name = strings.TrimSpace(token.String())
+		}
+		token.Reset()
+	}
+	//Adds the current rule to the result list and starts a new one
+	endRule := func() error {
+		endToken()
+		if current.name == "" {
+			if hasParams {
+				return fmt.Errorf("malformed validation tag '%s': parameters provided without a rule name", tag)
+			}
+		} else {
+			rules = append(rules, current)
+		}
+		current = tagRule{}
+		hasParams = false
+		return nil
+	}
+
+	for index := 0; index < len(tag); index++ {
+		char := tag[index]
+		switch {
+		case char == tagEscape:
+			if index+1 == len(tag) {
+				return nil, fmt.Errorf("malformed validation tag '%s': dangling escape character", tag)
+			}
+			next := tag[index+1]
+			if next == tagRuleSeparator || next == tagParamsStart || next == tagParamSeparator || next == tagEscape {
+				token.WriteByte(next)
+				index++
+			} else {
+				token.WriteByte(char)
+			}
+		case char == tagRuleSeparator:
+			if err := endRule(); err != nil {
+				return nil, err
+			}
+		case char == tagParamsStart && !hasParams:
+			endToken()
+			hasParams = true
+		case char == tagParamSeparator && hasParams:
+			endToken()
+		default:
+			token.WriteByte(char)
+		}
+	}
+	if err := endRule(); err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+//Builds the path of a struct field from the path of its parent struct and the field name (e.g. "Inner.C")
+func fieldPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+//Returns the type of the values a struct field holds, skipping the pointer indirections (e.g. "*int" -> "int")
+func fieldType(field reflect.StructField) reflect.Type {
+	t := field.Type
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+//Checks if a field of the struct found at "position" is a sub struct whose fields must be processed recursively
+//Sub structs and exported pointers to sub structs are processed, with the exception of the pointers to a struct type
+//that already contains the field (e.g. "Next *Node" inside "Node"), which would lead to an endless recursion
+//Returns the type of the sub struct
+func (p structPosition) nested(field reflect.StructField) (reflect.Type, bool) {
+	switch {
+	case field.Type.Kind() == reflect.Struct:
+		return field.Type, true
+	case field.Type.Kind() == reflect.Ptr && field.Type.Elem().Kind() == reflect.Struct && field.PkgPath == "":
+		if p.isParent(field.Type.Elem()) {
+			return nil, false
+		}
+		return field.Type.Elem(), true
+	default:
+		return nil, false
+	}
+}
+
+//Builds the position of a sub struct of type "nested", found in the given field of the struct found at "position"
+//If the field has the "datakey" tag, it becomes the prefix of the sub struct keys (e.g. "billing" -> "billing.city"),
+//otherwise the sub struct keys share the prefix of the struct
+func (p structPosition) child(field reflect.StructField, nested reflect.Type) structPosition {
+	prefix := p.prefix
+	if field.Tag.Get(tagMapKey) != "" {
+		prefix = p.key(field)
+	}
+
+	return structPosition{
+		path:    fieldPath(p.path, field.Name),
+		prefix:  prefix,
+		parents: p.withParent(nested),
+	}
+}
+
+//Builds the position of a slice of sub structs, found in the given field of the struct found at "position"
+//The "key" parameter is the map key linked to the field, which becomes the prefix of the elements
+func (p structPosition) with(field reflect.StructField, key string) structPosition {
+	return structPosition{
+		path:    fieldPath(p.path, field.Name),
+		prefix:  key,
+		parents: p.parents,
+	}
+}
+
+//Builds the position of an element of type "nested" of the slice of sub structs found at "position"
+func (p structPosition) element(element mapElement, nested reflect.Type) structPosition {
+	return structPosition{
+		path:    fmt.Sprintf("%s[%d]", p.path, element.index),
+		prefix:  element.prefix,
+		parents: p.withParent(nested),
+	}
+}
+
+//Returns the map key linked to a field of the struct found at "position": the "datakey" tag of the field, preceded
+//by the key prefix of the struct (e.g. "billing.city", "items[0].sku")
+func (p structPosition) key(field reflect.StructField) string {
+	mapKey := field.Tag.Get(tagMapKey)
+	if mapKey == "" || p.prefix == "" {
+		return mapKey
+	}
+
+	return p.prefix + "." + mapKey
+}
+
+//Checks if the given type is the type of the struct found at "position" or of one of the structs that contain it
+func (p structPosition) isParent(t reflect.Type) bool {
+	for _, parent := range p.parents {
+		if parent == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Returns the parent types of the struct found at "position", with the addition of the given type
+func (p structPosition) withParent(parent reflect.Type) []reflect.Type {
+	parents := make([]reflect.Type, len(p.parents), len(p.parents)+1)
+	copy(parents, p.parents)
+
+	return append(parents, parent)
+}
+
+//Checks if at least one of the keys linked to the fields of a struct type (or of its sub structs) is present in the map
+//When the struct has a key prefix, any map key starting with the prefix counts (e.g. "items[0].sku" for "items")
+func containsKeys(m map[string]string, t reflect.Type, position structPosition) bool {
+	for _, kf := range structKeys(t, position) {
+		if _, ok := m[kf.key]; ok {
+			return true
+		}
+	}
+	if position.prefix == "" {
+		return false
+	}
+	for key := range m {
+		if strings.HasPrefix(key, position.prefix+".") || strings.HasPrefix(key, position.prefix+"[") {
+			return true
+		}
+	}
+
+	return false
+}
+
+//Lists the elements of a slice of sub structs found in the map under the given key, sorted by index
+//Both the "key[n]" and the "key.n" notations are accepted (e.g. "items[0].sku", "items.0.sku"); when an index is
+//used with both notations, the prefix of the elements is the one of the "key[n]" notation
+//Only canonical indexes are taken into account (e.g. "items[01].sku" is ignored)
+func findElements(m map[string]string, key string) []mapElement {
+	prefixes := map[int]string{}
+	for mapKey := range m {
+		var rest string
+		var bracket bool
+		switch {
+		case strings.HasPrefix(mapKey, key+"["):
+			rest, bracket = mapKey[len(key)+1:], true
+		case strings.HasPrefix(mapKey, key+"."):
+			rest = mapKey[len(key)+1:]
+		default:
+			continue
+		}
+
+		//Extract the index, which ends at the closing bracket or at the next dot
+		var end, next int
+		if bracket {
+			end = strings.IndexByte(rest, ']')
+			next = end + 1
+		} else {
+			end = strings.IndexByte(rest, '.')
+			if end < 0 {
+				end = len(rest)
+			}
+			next = end
+		}
+		if end <= 0 || (next < len(rest) && rest[next] != '.') {
+			continue
+		}
+		index, err := strconv.Atoi(rest[:end])
+		if err != nil || index < 0 || strconv.Itoa(index) != rest[:end] {
+			continue
+		}
+
+		prefix := mapKey[:len(key)+1+next]
+		if current, ok := prefixes[index]; !ok || (bracket && !strings.HasSuffix(current, "]")) {
+			prefixes[index] = prefix
+		}
+	}
+
+	elements := make([]mapElement, 0, len(prefixes))
+	for index, prefix := range prefixes {
+		elements = append(elements, mapElement{index: index, prefix: prefix})
+	}
+	sort.Slice(elements, func(i, j int) bool {
+		return elements[i].index < elements[j].index
+	})
+
+	return elements
+}
+
+//Checks that the indexes of the elements of a slice of sub structs found in the map start from 0 and have no gaps
+//(e.g. "items[0]" and "items[2]" without "items[1]"), since the element "n" of the map is stored at the index "n" of
+//the slice and a missing element would be left unvalidated; it is not applied on the slices of pointers to sub
+//structs, since their missing elements are left nil
+//"path" and "key" are the field path and the map key of the slice; the FieldError names the first missing element
+func checkElementIndexes(elements []mapElement, path string, key string) *FieldError {
+	for index, element := range elements {
+		if element.index != index {
+			return &FieldError{Field: fmt.Sprintf("%s[%d]", path, index), Key: fmt.Sprintf("%s[%d]", key, index),
+				Err: fmt.Errorf("element %d is missing, the elements must be numbered from 0 without gaps", index)}
+		}
+	}
+
+	return nil
+}
+
+//Lists the map keys linked to the fields of a struct type and of its sub structs, in the order of the fields
+func structKeys(t reflect.Type, position structPosition) []keyField {
+	keys := make([]keyField, 0, t.NumField())
+	for index := 0; index < t.NumField(); index++ {
+		field := t.Field(index)
+		if nestedType, ok := position.nested(field); ok {
+			keys = append(keys, structKeys(nestedType, position.child(field, nestedType))...)
+		}
+		if mapKey := position.key(field); mapKey != "" {
+			keys = append(keys, keyField{key: mapKey, path: fieldPath(position.path, field.Name), field: field})
+		}
+	}
+
+	return keys
+}
+
+//Returns a copy of the map data, so that it can be changed without changing the map provided by the user
+func copyMap(m map[string]string) map[string]string {
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[key] = value
+	}
+
+	return result
+}
+
+//Checks if a type is a slice or an array
+func isSequence(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+}
+
+//Returns the separator of the elements inside the map value linked to a slice or array field
+//It is either the value of the "datasep" tag or the default separator (",")
+func fieldSeparator(field reflect.StructField) string {
+	if separator := field.Tag.Get(tagSeparator); separator != "" {
+		return separator
+	}
+
+	return defaultSeparator
+}
+
+//Splits the map value linked to a slice or array field into elements; an empty value has no elements
+func splitElements(value string, separator string) []string {
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, separator)
+}
+
+//Describes the elements of a slice, array or map field as a struct field, so that the rules applied on them see the
+//element type (e.g. "int" for "Ids []int" or "Counts map[string]int"); any other field is returned as it is
+func elementField(field reflect.StructField) reflect.StructField {
+	if t := fieldType(field); isSequence(t) || t.Kind() == reflect.Map {
+		field.Type = t.Elem()
+	}
+
+	return field
+}
+
+//Describes the keys of a map field as a struct field, so that the rules applied on them see the key type
+//(e.g. "string" for "Labels map[string]string"); any other field is returned as it is
+func entryKeyField(field reflect.StructField) reflect.StructField {
+	if t := fieldType(field); t.Kind() == reflect.Map {
+		field.Type = t.Key()
+	}
+
+	return field
+}
+
+//Lists the entries of a map field found in the map under the given key, sorted by name
+//Both the "key.name" and the "key[name]" notations are accepted (e.g. "label.env", "label[env]"); when a name is
+//used with both notations, the value of the "key[name]" notation is used
+func findEntries(m map[string]string, key string) []mapEntry {
+	keys := map[string]string{}
+	for mapKey := range m {
+		var name string
+		var bracket bool
+		switch {
+		case strings.HasPrefix(mapKey, key+"[") && strings.HasSuffix(mapKey, "]"):
+			name, bracket = mapKey[len(key)+1:len(mapKey)-1], true
+		case strings.HasPrefix(mapKey, key+"."):
+			name = mapKey[len(key)+1:]
+		default:
+			continue
+		}
+		if name == "" {
+			continue
+		}
+
+		if _, ok := keys[name]; !ok || bracket {
+			keys[name] = mapKey
+		}
+	}
+
+	entries := make([]mapEntry, 0, len(keys))
+	for name, mapKey := range keys {
+		entries = append(entries, mapEntry{name: name, key: mapKey})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+
+	return entries
+}
+
+//Builds the map seen by the rules of a map field: it contains the entries of the field (see findEntries) and, if
+//there is at least one entry, the key of the field linked to the list of entry names
+//Rules like "required" or "minlen" can then be applied on the field itself
+func entriesMap(m map[string]string, key string, separator string) map[string]string {
+	entries := findEntries(m, key)
+	result := make(map[string]string, len(entries)+1)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		result[entry.key] = m[entry.key]
+		names = append(names, entry.name)
+	}
+	if len(entries) > 0 {
+		result[key] = strings.Join(names, separator)
+	}
+
+	return result
+}
+
+//Splits the rules of a field at the "dive" modifier: the rules before it are applied on the field and the rules
+//after it on each one of the field elements
+//Returns true if the "dive" modifier is present
+func splitDive(rules []tagRule) ([]tagRule, []tagRule, bool) {
+	for index, rule := range rules {
+		if rule.name == ruleDive {
+			return rules[:index], rules[index+1:], true
+		}
+	}
+
+	return rules, nil, false
+}
+
+//Splits the rules applied on the elements of a map field at the "keys" and "endkeys" modifiers: the rules between
+//them are applied on the keys of the map and the remaining rules on its values
+//Example: "dive,keys,minlen=2,endkeys,int" -> keys: ["minlen=2"], values: ["int"]
+//Returns true if the "keys" modifier is present
+func splitKeys(rules []tagRule) ([]tagRule, []tagRule, bool, error) {
+	if len(rules) == 0 || rules[0].name != ruleKeys {
+		for _, rule := range rules {
+			if rule.name == ruleKeys || rule.name == ruleEndKeys {
+				return nil, nil, false, fmt.Errorf("rule '%s' must directly follow the rule '%s'", ruleKeys, ruleDive)
+			}
+		}
+		return nil, rules, false, nil
+	}
+	for index, rule := range rules {
+		if rule.name == ruleEndKeys {
+			return rules[1:index], rules[index+1:], true, nil
+		}
+	}
+
+	return nil, nil, false, fmt.Errorf("rule '%s' has no matching '%s'", ruleKeys, ruleEndKeys)
+}
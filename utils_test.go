@@ -0,0 +1,188 @@
+package validator
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestUtils_parseRules(t *testing.T) {
+	testdata := []struct {
+		in          string
+		out         []tagRule
+		noErrorFlag bool
+	}{
+		{
+			"",
+			[]tagRule{},
+			true,
+		},
+		{
+			"required, int",
+			[]tagRule{{"required", nil}, {"int", nil}},
+			true,
+		},
+		{
+			"required,,int",
+			[]tagRule{{"required", nil}, {"int", nil}},
+			true,
+		},
+		{
+			"min=3,max=10,oneof=a|b|c",
+			[]tagRule{{"min", []string{"3"}}, {"max", []string{"10"}}, {"oneof", []string{"a", "b", "c"}}},
+			true,
+		},
+		{
+			`oneof=a\,b|c\|d|e\=f|g\\`,
+			[]tagRule{{"oneof", []string{"a,b", "c|d", "e=f", `g\`}}},
+			true,
+		},
+		{
+			`regex=^\d+$`,
+			[]tagRule{{"regex", []string{`^\d+$`}}},
+			true,
+		},
+		{
+			"eq=a=b",
+			[]tagRule{{"eq", []string{"a=b"}}},
+			true,
+		},
+		{
+			"min=",
+			[]tagRule{{"min", []string{""}}},
+			true,
+		},
+		{
+			"=3",
+			nil,
+			false,
+		},
+		{
+			`required,min=3\`,
+			nil,
+			false,
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestParseRules_"+strconv.Itoa(i), func(t *testing.T) {
+			result, err := parseRules(td.in)
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(result, td.out)) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestUtils_findElements(t *testing.T) {
+	testdata := []struct {
+		m   map[string]string
+		out []mapElement
+	}{
+		{
+			map[string]string{"items": "x", "item[0].sku": "a"},
+			[]mapElement{},
+		},
+		{
+			map[string]string{"items[1].sku": "b", "items[0].sku": "a", "items[0].qty": "1"},
+			[]mapElement{{0, "items[0]"}, {1, "items[1]"}},
+		},
+		{
+			map[string]string{"items.2.sku": "c", "items.0": "a"},
+			[]mapElement{{0, "items.0"}, {2, "items.2"}},
+		},
+		{
+			map[string]string{"items.0.sku": "a", "items[0].qty": "1"},
+			[]mapElement{{0, "items[0]"}},
+		},
+		{
+			map[string]string{"items[01].sku": "a", "items[-1].sku": "b", "items[x].sku": "c", "items[0]sku": "d",
+				"items.sku": "e", "items[].sku": "f"},
+			[]mapElement{},
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestFindElements_"+strconv.Itoa(i), func(t *testing.T) {
+			if result := findElements(td.m, "items"); !reflect.DeepEqual(result, td.out) {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestUtils_findEntries(t *testing.T) {
+	testdata := []struct {
+		m   map[string]string
+		out []mapEntry
+	}{
+		{
+			map[string]string{"label": "x", "labels.a": "1", "label[]": "2", "label.": "3"},
+			[]mapEntry{},
+		},
+		{
+			map[string]string{"label.env": "prod", "label[team]": "core", "label.a.b": "c"},
+			[]mapEntry{{"a.b", "label.a.b"}, {"env", "label.env"}, {"team", "label[team]"}},
+		},
+		{
+			map[string]string{"label.env": "prod", "label[env]": "dev"},
+			[]mapEntry{{"env", "label[env]"}},
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestFindEntries_"+strconv.Itoa(i), func(t *testing.T) {
+			if result := findEntries(td.m, "label"); !reflect.DeepEqual(result, td.out) {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestUtils_splitKeys(t *testing.T) {
+	testdata := []struct {
+		in          []tagRule
+		keys        []tagRule
+		values      []tagRule
+		noErrorFlag bool
+	}{
+		{
+			[]tagRule{{"int", nil}},
+			nil,
+			[]tagRule{{"int", nil}},
+			true,
+		},
+		{
+			[]tagRule{{"keys", nil}, {"minlen", []string{"2"}}, {"endkeys", nil}, {"int", nil}},
+			[]tagRule{{"minlen", []string{"2"}}},
+			[]tagRule{{"int", nil}},
+			true,
+		},
+		{
+			[]tagRule{{"keys", nil}, {"minlen", []string{"2"}}},
+			nil,
+			nil,
+			false,
+		},
+		{
+			[]tagRule{{"int", nil}, {"keys", nil}, {"endkeys", nil}},
+			nil,
+			nil,
+			false,
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestSplitKeys_"+strconv.Itoa(i), func(t *testing.T) {
+			keys, values, _, err := splitKeys(td.in)
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(keys, td.keys) ||
+				!reflect.DeepEqual(values, td.values)) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
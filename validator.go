@@ -32,6 +32,7 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"reflect"
+	"regexp"
 	"strings"
 	"sync"
 )
@@ -41,8 +42,11 @@ const (
 
 	//private
 	//struct's tag keys
-	tagMapKey   string = "datakey"
-	tagValidate string = "validate"
+	tagMapKey    string = "datakey"
+	tagValidate  string = "validate"
+	tagSeparator string = "datasep"
+	tagDefault   string = "default"
+	tagTransform string = "transform"
 
 	//rule names
 	ruleRequired string = "required"
@@ -50,12 +54,87 @@ const (
 	ruleUnsigned string = "unsigned"
 	ruleTime     string = "time"
 	ruleBool     string = "bool"
+	ruleMin      string = "min"
+	ruleMax      string = "max"
+	ruleBetween  string = "between"
+	ruleLen      string = "len"
+	ruleMinLen   string = "minlen"
+	ruleMaxLen   string = "maxlen"
+	ruleEmail    string = "email"
+	ruleURL      string = "url"
+	ruleURI      string = "uri"
+	ruleUUID     string = "uuid"
+	ruleHostname string = "hostname"
+	ruleFQDN     string = "fqdn"
+	ruleIP       string = "ip"
+	ruleIPv4     string = "ipv4"
+	ruleIPv6     string = "ipv6"
+	ruleCIDR     string = "cidr"
+	ruleMAC      string = "mac"
+	ruleBase64   string = "base64"
+	ruleHex      string = "hex"
+	ruleSemver   string = "semver"
+	ruleRegex    string = "regex"
+	rulePattern  string = "pattern"
+	ruleOneOf    string = "oneof"
+	ruleOneOfCI  string = "oneofci"
+	ruleEnum     string = "enum"
+	ruleSingle   string = "single"
+
+	//conditional rule names
+	ruleRequiredIf      string = "required_if"
+	ruleRequiredUnless  string = "required_unless"
+	ruleRequiredWith    string = "required_with"
+	ruleRequiredWithAll string = "required_with_all"
+	ruleRequiredWithout string = "required_without"
+	ruleExcludedIf      string = "excluded_if"
+	ruleExcludedWith    string = "excluded_with"
+
+	//value rule names
+	ruleEqField string = "eqfield"
+	ruleNeField string = "nefield"
+	ruleGtField string = "gtfield"
+	ruleLtField string = "ltfield"
+
+	//rule name of the failures returned by the Validate and ValidateWith methods of the structs
+	ruleValidate string = "validate"
+
+	//rule modifiers
+	ruleDive    string = "dive"
+	ruleKeys    string = "keys"
+	ruleEndKeys string = "endkeys"
+
+	//transformer names
+	transformTrim     string = "trim"
+	transformLower    string = "lower"
+	transformUpper    string = "upper"
+	transformCollapse string = "collapse"
+
+	//maximum number of elements of a slice of sub structs (e.g. up to "items[9999].sku")
+	maxElements int = 10000
+
+	//separator of the elements inside the map values linked to slice and array fields, unless the field has
+	//the "datasep" tag
+	defaultSeparator string = ","
 
 	//converter types
-	convertInt    string = "int"
-	convertString string = "string"
-	convertTime   string = "time.Time"
-	convertBool   string = "bool"
+	convertInt      string = "int"
+	convertInt8     string = "int8"
+	convertInt16    string = "int16"
+	convertInt32    string = "int32"
+	convertInt64    string = "int64"
+	convertUint     string = "uint"
+	convertUint8    string = "uint8"
+	convertUint16   string = "uint16"
+	convertUint32   string = "uint32"
+	convertUint64   string = "uint64"
+	convertUintptr  string = "uintptr"
+	convertFloat32  string = "float32"
+	convertFloat64  string = "float64"
+	convertString   string = "string"
+	convertTime     string = "time.Time"
+	convertDuration string = "time.Duration"
+	convertBool     string = "bool"
 )
 
 type (
@@ -65,13 +144,82 @@ type (
 	//ConverterMappings is a map that connects a string type name to a converter function; changes the string value to
 	//the desired type
 	//Converter example: "MyStruct" -> ConvertToMyStruct()
+	//KindConverterMappings is a map that connects a reflect.Kind to a converter function; used for the types that have
+	//no converter registered under their name (e.g. "type UserID int64" uses the converter of reflect.Int64)
+	//FieldRuleMappings is a map that connects a string rule name to a builtin rule that depends on the struct field it
+	//is applied on (e.g. "min" compares numbers for int fields and lengths for string fields)
+	//ValueRuleMappings is a map that connects a string rule name to a rule applied on the converted value of the field,
+	//after the initialization (e.g. "gtfield" compares two time.Time values); see RegisterValueRule
+	//ParamCheckers is a map that connects the name of a builtin rule to the check of its params, used by Compile to
+	//report the malformed params (e.g. "min=abc") upfront; the checker of a rule is removed when the rule is replaced
+	//by a custom one
+	//Patterns is a map that connects a pattern name to a compiled regular expression, used by the "pattern" rule
+	//RegexCache holds the regular expressions compiled by the "regex" rule so that they are compiled only once
+	//Enums is a map that connects a type name to the list of values the type accepts (see RegisterEnum)
+	//Transformers is a map that connects a transformer name to a function that normalizes a map value before the
+	//validation and the conversion (see RegisterTransformer)
+	//Plans holds the compiled plan of each struct type (see structPlan) and ConverterCache the converter resolved for
+	//each type (see resolveConverter), so that they are computed only once per type
+	//DefaultErrors holds the result of the check of the "default" tags of each struct type, so that the default values
+	//are converted only once per type
+	//The three caches are reset when a rule or a converter is registered
+	//CollectAll flag signifies that the validation continues after a failed rule and returns all the failures at once
+	//StrictValues flag signifies that several values provided for a scalar field by a multi-value input are a failure
 	Validator struct {
 		//public
 
 		//private
-		ruleMappings      map[string]func(mapKey string, m map[string]string, params ...string) error
-		converterMappings map[string]func(value string, params ...string) (interface{}, error)
-		isInit            bool
+		ruleMappings       map[string]func(mapKey string, m map[string]string, params ...string) error
+		converterMappings  map[string]func(value string, params ...string) (interface{}, error)
+		kindConverters     map[reflect.Kind]func(value string, params ...string) (interface{}, error)
+		fieldRuleMappings  map[string]func(fc fieldContext, params ...string) error
+		valueRuleMappings  map[string]func(value reflect.Value, lookup FieldLookup, params ...string) error
+		structRuleMappings map[string]func(value reflect.Value, lookup FieldLookup, params ...string) error
+		paramCheckers      map[string]func(t reflect.Type, params ...string) error
+		patterns           map[string]*regexp.Regexp
+		regexCache         sync.Map
+		enums              map[string][]string
+		transformers       map[string]func(value string, params ...string) (string, error)
+		plans              sync.Map
+		converterCache     sync.Map
+		defaultErrors      sync.Map
+		mutex              sync.RWMutex
+		generation         uint64
+		collectAll         bool
+		strictValues       bool
+		isInit             bool
+	}
+
+	//The position of a struct inside the processed struct
+	//"path" is the path of the struct (e.g. "Inner"), used to build the field paths, and "parents" contains the types
+	//of the struct and of the structs that contain it, used to avoid the endless recursion on self referencing types
+	//"prefix" is the prefix of the map keys linked to the struct fields (e.g. "billing" or "items[0]")
+	structPosition struct {
+		path    string
+		prefix  string
+		parents []reflect.Type
+	}
+
+	//A converter resolved for a type, together with the type name passed to it as param; "fn" is nil if the type has
+	//no converter
+	resolvedConverter struct {
+		fn       func(value string, params ...string) (interface{}, error)
+		typeName string
+	}
+
+	//The information about the validated field that is provided to the field rules
+	//"key" is the map key linked to the field, "m" is the validated map and "field" is the struct field
+	//"data" is the whole map data and "prefix" the key prefix of the struct that contains the field, used by the
+	//conditional rules to find the keys they reference; "m" may only contain the values of the field (e.g. for
+	//slices of sub structs and map fields)
+	//"lists" contains the elements of the slice and array fields provided as separate values (see elementLists)
+	fieldContext struct {
+		key    string
+		m      map[string]string
+		data   map[string]string
+		lists  elementLists
+		prefix string
+		field  reflect.StructField
 	}
 )
 
@@ -104,18 +252,125 @@ func GetInstance() *Validator {
 func (v *Validator) initValidator() {
 	v.ruleMappings = make(map[string]func(mapKey string, m map[string]string, params ...string) error)
 	v.converterMappings = make(map[string]func(value string, params ...string) (interface{}, error))
+	v.kindConverters = make(map[reflect.Kind]func(value string, params ...string) (interface{}, error))
+	v.fieldRuleMappings = make(map[string]func(fc fieldContext, params ...string) error)
+	v.valueRuleMappings = make(map[string]func(value reflect.Value, lookup FieldLookup, params ...string) error)
+	v.structRuleMappings = make(map[string]func(value reflect.Value, lookup FieldLookup, params ...string) error)
+	v.paramCheckers = make(map[string]func(t reflect.Type, params ...string) error)
+	v.patterns = make(map[string]*regexp.Regexp)
+	v.enums = make(map[string][]string)
+	v.transformers = make(map[string]func(value string, params ...string) (string, error))
 
 	v.ruleMappings[ruleRequired] = checkRequired
 	v.ruleMappings[ruleInt] = checkInt
 	v.ruleMappings[ruleUnsigned] = checkUnsigned
 	v.ruleMappings[ruleTime] = checkTime
 	v.ruleMappings[ruleBool] = checkBool
+	v.ruleMappings[ruleEmail] = checkEmail
+	v.ruleMappings[ruleURL] = checkURL
+	v.ruleMappings[ruleURI] = checkURI
+	v.ruleMappings[ruleUUID] = checkUUID
+	v.ruleMappings[ruleHostname] = checkHostname
+	v.ruleMappings[ruleFQDN] = checkFQDN
+	v.ruleMappings[ruleIP] = checkIP
+	v.ruleMappings[ruleIPv4] = checkIPv4
+	v.ruleMappings[ruleIPv6] = checkIPv6
+	v.ruleMappings[ruleCIDR] = checkCIDR
+	v.ruleMappings[ruleMAC] = checkMAC
+	v.ruleMappings[ruleBase64] = checkBase64
+	v.ruleMappings[ruleHex] = checkHex
+	v.ruleMappings[ruleSemver] = checkSemver
+	v.ruleMappings[ruleRegex] = v.checkRegex
+	v.ruleMappings[rulePattern] = v.checkPattern
+	v.ruleMappings[ruleOneOf] = checkOneOf
+	v.ruleMappings[ruleOneOfCI] = checkOneOfCI
+
+	v.fieldRuleMappings[ruleMin] = checkMin
+	v.fieldRuleMappings[ruleMax] = checkMax
+	v.fieldRuleMappings[ruleBetween] = checkBetween
+	v.fieldRuleMappings[ruleLen] = checkLen
+	v.fieldRuleMappings[ruleMinLen] = checkMinLen
+	v.fieldRuleMappings[ruleMaxLen] = checkMaxLen
+	v.fieldRuleMappings[ruleRequiredIf] = checkRequiredIf
+	v.fieldRuleMappings[ruleRequiredUnless] = checkRequiredUnless
+	v.fieldRuleMappings[ruleRequiredWith] = checkRequiredWith
+	v.fieldRuleMappings[ruleRequiredWithAll] = checkRequiredWithAll
+	v.fieldRuleMappings[ruleRequiredWithout] = checkRequiredWithout
+	v.fieldRuleMappings[ruleExcludedIf] = checkExcludedIf
+	v.fieldRuleMappings[ruleExcludedWith] = checkExcludedWith
+
+	v.valueRuleMappings[ruleEqField] = checkEqField
+	v.valueRuleMappings[ruleNeField] = checkNeField
+	v.valueRuleMappings[ruleGtField] = checkGtField
+	v.valueRuleMappings[ruleLtField] = checkLtField
+
+	v.structRuleMappings[ruleRequired] = checkValueRequired
+	v.structRuleMappings[ruleInt] = checkValueInt
+	v.structRuleMappings[ruleUnsigned] = checkValueUnsigned
+	v.structRuleMappings[ruleTime] = checkValueTime
+	v.structRuleMappings[ruleBool] = checkValueBool
+	v.structRuleMappings[ruleMin] = checkValueMin
+	v.structRuleMappings[ruleMax] = checkValueMax
+	v.structRuleMappings[ruleBetween] = checkValueBetween
+	v.structRuleMappings[ruleLen] = checkValueLen
+	v.structRuleMappings[ruleMinLen] = checkValueMinLen
+	v.structRuleMappings[ruleMaxLen] = checkValueMaxLen
+
+	for _, rule := range []string{ruleRequired, ruleInt, ruleUnsigned, ruleTime, ruleBool, ruleEmail, ruleURL, ruleURI,
+		ruleHostname, ruleFQDN, ruleIP, ruleIPv4, ruleIPv6, ruleCIDR, ruleMAC, ruleBase64, ruleHex, ruleSemver} {
+		v.paramCheckers[rule] = checkNoParams
+	}
+	for _, rule := range []string{ruleMin, ruleMax, ruleLen, ruleMinLen, ruleMaxLen} {
+		v.paramCheckers[rule] = checkBoundParam
+	}
+	for _, rule := range []string{ruleRequiredIf, ruleRequiredUnless, ruleExcludedIf} {
+		v.paramCheckers[rule] = checkKeyValueParams
+	}
+	for _, rule := range []string{ruleRequiredWith, ruleRequiredWithAll, ruleRequiredWithout, ruleExcludedWith} {
+		v.paramCheckers[rule] = checkKeyParams
+	}
+	for _, rule := range []string{ruleEqField, ruleNeField, ruleGtField, ruleLtField} {
+		v.paramCheckers[rule] = checkFieldParam
+	}
+	v.paramCheckers[ruleBetween] = checkBetweenParams
+	v.paramCheckers[ruleUUID] = checkUUIDParams
+	v.paramCheckers[ruleRegex] = v.checkRegexParams
+	v.paramCheckers[rulePattern] = v.checkPatternParams
+	v.paramCheckers[ruleOneOf] = checkValueParams
+	v.paramCheckers[ruleOneOfCI] = checkValueParams
 
 	v.converterMappings[convertInt] = convertToInt
+	v.converterMappings[convertInt8] = convertToInt
+	v.converterMappings[convertInt16] = convertToInt
+	v.converterMappings[convertInt32] = convertToInt
+	v.converterMappings[convertInt64] = convertToInt
+	v.converterMappings[convertUint] = convertToInt
+	v.converterMappings[convertUint8] = convertToInt
+	v.converterMappings[convertUint16] = convertToInt
+	v.converterMappings[convertUint32] = convertToInt
+	v.converterMappings[convertUint64] = convertToInt
+	v.converterMappings[convertUintptr] = convertToInt
+	v.converterMappings[convertFloat32] = convertToFloat
+	v.converterMappings[convertFloat64] = convertToFloat
 	v.converterMappings[convertString] = convertToString
 	v.converterMappings[convertTime] = convertToTime
+	v.converterMappings[convertDuration] = convertToDuration
 	v.converterMappings[convertBool] = convertToBool
 
+	v.transformers[transformTrim] = transformToTrimmed
+	v.transformers[transformLower] = transformToLower
+	v.transformers[transformUpper] = transformToUpper
+	v.transformers[transformCollapse] = transformToCollapsed
+
+	for _, kind := range []reflect.Kind{reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr} {
+		v.kindConverters[kind] = convertToInt
+	}
+	v.kindConverters[reflect.Float32] = convertToFloat
+	v.kindConverters[reflect.Float64] = convertToFloat
+	v.kindConverters[reflect.String] = convertToString
+	v.kindConverters[reflect.Bool] = convertToBool
+
 	v.isInit = true
 }
 
@@ -126,50 +381,183 @@ func (v *Validator) initValidator() {
 //The m parameter is a map with string keys and string values, while i parameter is an
 //interface (normally a pointer to an empty struct)
 func (v *Validator) ValidateAndInit(m map[string]string, i interface{}) error {
+	t, err := v.target(i)
+	if err != nil {
+		return err
+	}
+
+	return v.validateAndInit(m, nil, t, nil)
+}
 
+//Checks the interface provided to the public APIs and returns the struct it points to
+func (v *Validator) target(i interface{}) (reflect.Value, error) {
 	//If the i parameter is not a pointer, return an exception
 	if reflect.ValueOf(i).Kind() != reflect.Ptr {
-		return fmt.Errorf("please provide a pointer to the interface")
+		return reflect.Value{}, fmt.Errorf("please provide a pointer to the interface")
 	}
 
 	//If the i parameter is not a pointer to a struct, return an exception
-	if reflect.Indirect(reflect.ValueOf(i)).Type().Kind() != reflect.Struct {
-		return fmt.Errorf("please provide a pointer to the struct")
+	if reflect.TypeOf(i).Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("please provide a pointer to the struct")
+	}
+	if reflect.ValueOf(i).IsNil() {
+		return reflect.Value{}, fmt.Errorf("please provide a non nil pointer to the struct")
 	}
 
 	//If the Validator is not initialized, return an error
 	if !v.isInit {
-		return fmt.Errorf("validator not initialized: call New()")
+		return reflect.Value{}, fmt.Errorf("validator not initialized: call New()")
 	}
 
 	//Using reflection get the concrete value of the pointer i through the Elem()
-	t := reflect.ValueOf(i).Elem()
+	return reflect.ValueOf(i).Elem(), nil
+}
 
-	//Validation step
-	//Check if the map values respect the rules defined on the struct's fields
-	//If the validation fails, return an error
-	err := v.checkRules(m, t)
+//Validates the map data based on the rules defined on the struct's tags, without initializing the struct
+//The transformers and the default values are applied as for ValidateAndInit, so the rules see the same values; the
+//value rules (e.g. "gtfield") and the Validate and ValidateWith methods are not called, since they need the converted
+//values of the fields
+//The i parameter is a pointer to a struct, which is left unchanged (e.g. &MyStruct{})
+func (v *Validator) Validate(m map[string]string, i interface{}) error {
+	t, err := v.target(i)
+	if err != nil {
+		return err
+	}
+
+	_, err = v.validateData(m, nil, t, nil)
+	return err
+}
+
+//Initializes the struct with the map data, without applying the rules defined on the struct's tags
+//Meant for the data that was already validated (e.g. with Validate); the transformers and the default values are
+//applied as for ValidateAndInit
+//The struct is left unchanged if a map value cannot be converted to the type of its field
+func (v *Validator) Init(m map[string]string, i interface{}) error {
+	t, err := v.target(i)
+	if err != nil {
+		return err
+	}
+
+	m, err = v.prepareData(m, nil, t.Type(), nil)
+	if err != nil {
+		return err
+	}
+	target := reflect.New(t.Type()).Elem()
+	target.Set(t)
+	err = v.initData(m, nil, target)
+	if err != nil {
+		return errors.Wrap(err, "error initializing struct with values")
+	}
+	t.Set(target)
+
+	return nil
+}
+
+//Validates the map data based on the rules of the struct type of a Schema, like Validate, without the need of a value
+//of that type
+//The Schema must have been compiled by this Validator
+func (v *Validator) Check(m map[string]string, schema *Schema) error {
+	if schema == nil || schema.validator != v {
+		return fmt.Errorf("please provide a schema compiled by this validator")
+	}
+
+	_, err := v.validateData(m, nil, reflect.New(schema.t).Elem(), nil)
+	return err
+}
+
+//Validates the map data and initializes the struct "t" with it
+//The "lists" parameter contains the elements of the slice and array fields provided as separate values (see
+//elementLists), nil if the map data is the only input
+//The "failures" parameter contains the failures found before the validation step (e.g. while reading the input);
+//they are returned together with the rule failures and prevent the initialization of the struct
+func (v *Validator) validateAndInit(m map[string]string, lists elementLists, t reflect.Value,
+	failures ValidationErrors) error {
+	m, err := v.validateData(m, lists, t, failures)
 	if err != nil {
-		return errors.Wrap(err, "error validation map values based on rules")
+		return err
 	}
 
 	//Struct initialization step
-	//Initialize the struct with the values from the map
+	//Initialize a copy of the struct with the values from the map, so that the struct is left unchanged if the
+	//initialization or the value rules fail
 	//If the data initialization fails, return an error
-	err = v.initData(m, t)
+	target := reflect.New(t.Type()).Elem()
+	target.Set(t)
+	err = v.initData(m, lists, target)
 	if err != nil {
 		return errors.Wrap(err, "error initializing struct with values")
 	}
 
+	//Value validation step
+	//Check if the converted values respect the value rules (e.g. "gtfield")
+	err = v.checkValueRules(m, target)
+	if err != nil {
+		return errors.Wrap(err, "error validation struct values based on rules")
+	}
+
+	//Struct validation step
+	//Call the Validate and ValidateWith methods of the initialized struct and of its sub structs
+	err = v.callHooks(target)
+	if err != nil {
+		return errors.Wrap(err, "error validation struct")
+	}
+	t.Set(target)
+
 	return nil
 }
 
+//Prepares the map data for the struct type "t" and validates it based on the rules defined on the struct's tags
+//Returns the prepared map data (see prepareData), used to initialize the struct
+func (v *Validator) validateData(m map[string]string, lists elementLists, t reflect.Value,
+	failures ValidationErrors) (map[string]string, error) {
+	m, err := v.prepareData(m, lists, t.Type(), &failures)
+	if err != nil {
+		return nil, err
+	}
+
+	//Validation step
+	//Check if the map values respect the rules defined on the struct's fields
+	//If the validation fails, return an error
+	err = v.checkRules(m, lists, t)
+	if ruleFailures, ok := err.(ValidationErrors); ok {
+		failures = append(failures, ruleFailures...)
+	} else if err != nil {
+		return nil, errors.Wrap(err, "error validation map values based on rules")
+	}
+	if len(failures) > 0 {
+		return nil, errors.Wrap(failures, "error validation map values based on rules")
+	}
+
+	return m, nil
+}
+
+//Normalizes the map data with the transformers of the struct type "t" and completes it with the default values
+//The elements provided as separate values ("lists") are transformed in place
+//The transformer failures are stored inside "failures"; if "failures" is nil, they are returned as error
+func (v *Validator) prepareData(m map[string]string, lists elementLists, t reflect.Type,
+	failures *ValidationErrors) (map[string]string, error) {
+	//Normalize the map values with the transformers of the "transform" tags (e.g. "trim", "lower")
+	m, transformFailures, err := v.applyTransformers(m, lists, t)
+	if err != nil {
+		return nil, errors.Wrap(err, "error transforming map values")
+	}
+	if failures != nil {
+		*failures = append(*failures, transformFailures...)
+	} else if len(transformFailures) > 0 {
+		return nil, errors.Wrap(transformFailures, "error transforming map values")
+	}
+
+	//Add the default values of the absent keys, so that both the rules and the converters see them
+	return v.applyDefaults(m, t)
+}
+
 //Used when user needs to add a custom rule
 //The parameter "ruleName" is the name of teh rule as specified int he validation tag of the struct's field
 //The second parameter is a function that needs to respect the required definition:
 //* "mapKey" string parameter which will be the map key to which the struct field will be linked to
 //* "m" a map with string keys and string values which will contains the data provided at the ValidateAndInit function
-//* "params" a list of optional arguments
+//* "params" a list of optional arguments, provided inside the validation tag after the rule name
+//  (e.g. `validate:"myRule=a|b"` will call the rule with the params "a" and "b")
 func (v *Validator) RegisterRule(ruleName string, rule func(mapKey string, m map[string]string, params ...string) error) error { //If the Validator is not initialized, return an error
 	if !v.isInit {
 		return fmt.Errorf("validator not initialized: call New()")
@@ -178,7 +566,42 @@ func (v *Validator) RegisterRule(ruleName string, rule func(mapKey string, m map
 		return fmt.Errorf("empty rule name provided")
 	}
 
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
 	v.ruleMappings[ruleName] = rule
+	//The custom rule replaces the builtin one for ValidateStruct as well, which calls it with the textual form of the
+	//values; a value based implementation can still be provided with RegisterValueRule
+	delete(v.structRuleMappings, ruleName)
+	delete(v.paramCheckers, ruleName)
+	v.invalidate()
+
+	return nil
+}
+
+//Used when the user needs to add a custom rule applied on the converted value of the field instead of the map value
+//The rule runs after the initialization and receives:
+//* "value" is the converted value of the field (e.g. a time.Time value for a time.Time field)
+//* "lookup" returns the converted value of another field of the same struct, referenced by its "datakey" tag
+//* "params" is the list of params provided inside the tag
+//The rule is applied only if the key of the field is present in the map; rules registered with RegisterRule under the
+//same name take precedence
+func (v *Validator) RegisterValueRule(ruleName string,
+	rule func(value reflect.Value, lookup FieldLookup, params ...string) error) error {
+	if !v.isInit {
+		return fmt.Errorf("validator not initialized: call New()")
+	}
+	if ruleName == "" {
+		return fmt.Errorf("empty rule name provided")
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.valueRuleMappings[ruleName] = rule
+	//The builtin map and field rules take precedence, so their params are still checked
+	_, isRule := v.ruleMappings[ruleName]
+	if _, isFieldRule := v.fieldRuleMappings[ruleName]; !isRule && !isFieldRule {
+		delete(v.paramCheckers, ruleName)
+	}
+	v.invalidate()
 
 	return nil
 }
@@ -195,92 +618,843 @@ func (v *Validator) RegisterConverter(toType string, converter func(value string
 	if toType == "" {
 		return fmt.Errorf("empty rule name provided")
 	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
 	v.converterMappings[toType] = converter
+	v.invalidate()
+
+	return nil
+}
+
+//Used when the user needs to add a converter for all the types of a given kind
+//The converter is used for the types that have no converter registered under their name (e.g. "type UserID int64"
+//uses the converter of reflect.Int64), and its result is converted to the field type via reflect.Value.Convert
+//The "params" of the converter contain the name of the kind (e.g. "int64")
+func (v *Validator) RegisterKindConverter(kind reflect.Kind,
+	converter func(value string, params ...string) (interface{}, error)) error {
+	if !v.isInit {
+		return fmt.Errorf("validator not initialized: call New()")
+	}
+	if kind == reflect.Invalid {
+		return fmt.Errorf("invalid kind provided")
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.kindConverters[kind] = converter
+	v.invalidate()
+
+	return nil
+}
+
+//Used when the user needs to add a custom transformer, referenced by the "transform" tag
+//(e.g. after RegisterTransformer("nodots", ...) the field tag can contain `transform:"trim,nodots"`)
+//The transformer receives the raw map value and the params of the tag and returns the normalized value; the
+//transformers of a field are applied in the order of the tag, before the rules and the conversion
+//An error returned by the transformer is reported as a failure of the field, with the transformer name as rule
+func (v *Validator) RegisterTransformer(name string,
+	transformer func(value string, params ...string) (string, error)) error {
+	if !v.isInit {
+		return fmt.Errorf("validator not initialized: call New()")
+	}
+	if name == "" {
+		return fmt.Errorf("empty transformer name provided")
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.transformers[name] = transformer
+
+	return nil
+}
+
+//Used when the user needs to register a named regular expression, referenced by the "pattern" rule
+//(e.g. after RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$") the field tag can contain `validate:"pattern=sku"`)
+//The expression is compiled right away and an error is returned if it is not valid
+func (v *Validator) RegisterPattern(name string, expr string) error {
+	if !v.isInit {
+		return fmt.Errorf("validator not initialized: call New()")
+	}
+	if name == "" {
+		return fmt.Errorf("empty pattern name provided")
+	}
+	pattern, err := regexp.Compile(expr)
+	if err != nil {
+		return errors.Wrapf(err, "invalid regular expression for pattern '%s'", name)
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.patterns[name] = pattern
+
+	return nil
+}
+
+//Used when the user needs to restrict the values accepted by a named type (e.g. "type Status string")
+//The parameter "typeName" is the name of the type as used by RegisterConverter (e.g. "models.Status") and "values"
+//is the list of accepted values
+//Every field of that type is validated against the list of values, without the need of a rule inside its tag
+func (v *Validator) RegisterEnum(typeName string, values ...string) error {
+	if !v.isInit {
+		return fmt.Errorf("validator not initialized: call New()")
+	}
+	if typeName == "" {
+		return fmt.Errorf("empty type name provided")
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("no values provided for enum '%s'", typeName)
+	}
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.enums[typeName] = values
 
 	return nil
 }
 
+//Configures how the Validator reacts to a failed rule
+//By default the validation stops at the first failed rule and returns its error
+//If "collect" is true, the validation walks every field and every rule and returns a ValidationErrors value
+//listing every failure; the struct is not initialized if any failure occurred
+func (v *Validator) SetCollectAllErrors(collect bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.collectAll = collect
+}
+
 //Validates the map data based on the rules defined on the struct's tags
 //If the provided struct contains several sub struct as fields, they too will be taken into account recursively
-func (v *Validator) checkRules(m map[string]string, t reflect.Value) error {
-	//Iterate over the list of struct fields
-	for index := 0; index < t.Type().NumField(); index++ {
+//When the Validator collects all the errors, the failures are returned together as ValidationErrors
+//The elements of the slice and array fields provided as separate values are found in "lists" (see elementLists)
+func (v *Validator) checkRules(m map[string]string, lists elementLists, t reflect.Value) error {
+	failures := ValidationErrors{}
+	err := v.collectRuleFailures(m, lists, t, structPosition{parents: []reflect.Type{t.Type()}}, &failures)
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return failures
+	}
+
+	return nil
+}
+
+//Applies the rules defined on the struct's tags and stores the failures inside "failures" as FieldError values
+//The "position" parameter is the position of the struct inside the validated struct
+//If the Validator does not collect all the errors, the first failure is returned right away
+//Errors that are not caused by the map data (e.g. a rule with no implementation) are always returned right away
+func (v *Validator) collectRuleFailures(m map[string]string, lists elementLists, t reflect.Value,
+	position structPosition, failures *ValidationErrors) error {
+	//Iterate over the list of struct fields, using the compiled plan of the struct type
+	for _, fp := range v.plan(t.Type()).fields {
 		//Get the current field from the struct
-		currField := t.Type().Field(index)
-		//If the current field is a sub struct, call the validation function recursively
-		if currField.Type.Kind() == reflect.Struct {
-			err := v.checkRules(m, t.Field(index))
-			if err != nil {
-				return err
+		currField := fp.field
+		path := fieldPath(position.path, currField.Name)
+		//If the current field is a sub struct or a pointer to a sub struct, call the validation function recursively
+		//Pointers to sub structs are optional: their rules are applied only if at least one of their keys is present
+		//in the map; only the types of the fields are needed, so the pointers are replaced by the zero value of the
+		//sub struct
+		if nestedType, ok := position.nested(currField); ok {
+			nested := t.Field(fp.index)
+			nestedPosition := position.child(currField, nestedType)
+			if currField.Type.Kind() == reflect.Ptr {
+				nested = reflect.Zero(nestedType)
+			}
+			if currField.Type.Kind() != reflect.Ptr || containsKeys(m, nestedType, nestedPosition) {
+				err := v.collectRuleFailures(m, lists, nested, nestedPosition, failures)
+				if err != nil {
+					return err
+				}
 			}
 		}
 
-		//Extract the list of rules from the tag "validate"
-		validationRules, isValidationKey := currField.Tag.Lookup(tagValidate)
-		//If the validation tag is present in the field tags apply the checks for each validation rule
-		if isValidationKey {
-			rules := strings.Split(validationRules, ",")
-			if len(rules) == 1 && rules[0] == "" {
-				continue
-			}
-			for _, ruleName := range rules {
-				stripedRuleName := strings.TrimSpace(ruleName)
-				//Extract the mapped function for the current rule and call it using the map data
-				//If the rule name is not mapped in the Validator, return an error
-				if ruleImpl, ok := v.ruleMappings[stripedRuleName]; ok {
-					if currField.Tag.Get(tagMapKey) == "" {
-						continue
-					}
-					err := ruleImpl(currField.Tag.Get(tagMapKey), m)
-					if err != nil {
+		//If the current field is a slice of sub structs, call the validation function recursively for each element
+		//found in the map (e.g. "items[0].sku", "items.1.sku")
+		//The rules of the field itself see the list of elements as its value, so they can check the number of
+		//elements (e.g. "required", "minlen")
+		mapKey := position.key(currField)
+		fc := fieldContext{key: mapKey, m: m, data: m, lists: lists, prefix: position.prefix, field: currField}
+		if fp.isStructList && mapKey != "" {
+			elements := findElements(m, mapKey)
+			if fieldType(currField).Elem().Kind() != reflect.Ptr {
+				if fieldErr := checkElementIndexes(elements, path, mapKey); fieldErr != nil {
+					if err := v.reportFailure(fieldErr, failures); err != nil {
 						return err
 					}
-				} else {
-					return fmt.Errorf("validation rule '%s' has no implementation. "+
-						"please use 'RegisterRule' to provide one", ruleName)
 				}
 			}
+			prefixes := make([]string, 0, len(elements))
+			for _, element := range elements {
+				prefixes = append(prefixes, element.prefix)
+				elementPosition := position.with(currField, mapKey).element(element, fp.elementType)
+				err := v.collectRuleFailures(m, lists, reflect.Zero(fp.elementType), elementPosition, failures)
+				if err != nil {
+					return err
+				}
+			}
+			fc.m = map[string]string{}
+			if len(elements) > 0 {
+				fc.m[mapKey] = strings.Join(prefixes, fp.separator)
+			}
+		}
+
+		//If the current field is a map, its entries are found in the map under prefixed keys (e.g. "label.env",
+		//"label[env]"); the rules of the field itself see the list of entry names as its value
+		if fp.isMap && mapKey != "" {
+			fc.m = entriesMap(m, mapKey, fp.separator)
+		}
+
+		//If the validation tag is present in the field tags apply the checks for each validation rule
+		//The rules following the "dive" modifier are applied on each element of slice and array fields and on each
+		//value of map fields (or on each key, between the "keys" and "endkeys" modifiers)
+		if fp.hasRules {
+			err := checkRuleTag(fp, path)
+			if err != nil {
+				return err
+			}
+			err = v.applyRules(fp.rules, fc, path, failures)
+			if err != nil {
+				return err
+			}
+			if fp.isDive {
+				err = v.applyElementRules(fp, fc, path, failures)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		//If the field type (or the element type for slice and array fields) is a registered enum, check that the map
+		//value (or each one of its elements) is one of the enum values
+		if mapKey != "" {
+			err := v.applyEnum(fc, path, failures)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Checks that the "validate" tag of a field is well formed and that its modifiers fit the field type: "dive" is used on
+//slice, array and map fields, "keys" and "endkeys" on map fields and no value rule follows "dive"
+func checkRuleTag(fp fieldPlan, path string) error {
+	if fp.rulesErr != nil {
+		return fp.rulesErr
+	}
+	kind := fieldType(fp.field).Kind()
+	if fp.isDive && !isSequence(fieldType(fp.field)) && kind != reflect.Map {
+		return fmt.Errorf("rule '%s' can only be used on slice, array and map fields, field '%s' is of type '%s'",
+			ruleDive, path, fp.field.Type)
+	}
+	for _, rule := range fp.elementRules {
+		if rule.valueRule != nil {
+			return fmt.Errorf("rule '%s' is applied on the converted value of the field and cannot follow the "+
+				"rule '%s', field '%s'", rule.name, ruleDive, path)
+		}
+		if (rule.name == ruleKeys || rule.name == ruleEndKeys) && kind != reflect.Map {
+			return fmt.Errorf("rule '%s' can only be used on map fields, field '%s' is of type '%s'", rule.name,
+				path, fp.field.Type)
+		}
+	}
+	if fp.keysErr != nil {
+		return errors.Wrapf(fp.keysErr, "invalid rules for field '%s'", path)
+	}
+
+	return nil
+}
+
+//Applies a list of rules on a field
+//For each rule, call the function it was resolved to (see compileRules) using the map data and the rule params
+//The rules registered by the user take precedence over the builtin field rules
+//If the rule name is not mapped in the Validator or the rule is misconfigured (see configError), return an error
+func (v *Validator) applyRules(rules []compiledRule, fc fieldContext, path string, failures *ValidationErrors) error {
+	for _, rule := range rules {
+		if !rule.isDefined() {
+			return fmt.Errorf("validation rule '%s' has no implementation. "+
+				"please use 'RegisterRule' to provide one", rule.name)
+		}
+		//The value rules are applied after the initialization, on the converted values (see checkValueRules)
+		if fc.key == "" || rule.valueRule != nil {
+			continue
+		}
+
+		var err error
+		if rule.rule != nil {
+			err = rule.rule(fc.key, fc.m, rule.params...)
+		} else {
+			err = rule.fieldRule(fc, rule.params...)
+		}
+		if err != nil {
+			if err := ruleConfigError(err, rule.name, path); err != nil {
+				return err
+			}
+			fieldErr := toFieldError(err, path, fc.key, rule.name, fc.m[fc.key], rule.params)
+			if err := v.reportFailure(fieldErr, failures); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Applies the rules following the "dive" modifier on each element of a slice or array field
+//Each element is validated as if it was the only value of the map, under the key of the field, while the field rules
+//see it as a value of the element type; the failures have the element index in their field path (e.g. "Tags[1]")
+func (v *Validator) applyElementRules(fp fieldPlan, fc fieldContext, path string, failures *ValidationErrors) error {
+	if fieldType(fc.field).Kind() == reflect.Map {
+		return v.applyEntryRules(fp, fc, path, failures)
+	}
+	//If the key is not present in the map, the rules are only checked for their existence
+	mapValue, ok := fc.m[fc.key]
+	if !ok || fc.key == "" {
+		return v.applyRules(fp.elementRules, fieldContext{field: elementField(fc.field)}, path, failures)
+	}
+
+	for index, element := range fc.lists.split(fc.key, mapValue, fp.separator) {
+		elementContext := fieldContext{key: fc.key, m: map[string]string{fc.key: element}, field: elementField(fc.field)}
+		err := v.applyRules(fp.elementRules, elementContext, fmt.Sprintf("%s[%d]", path, index), failures)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Applies the rules following the "dive" modifier on each entry of a map field
+//The rules between the "keys" and "endkeys" modifiers are applied on the entry names as values of the key type, the
+//remaining rules on the entry values as values of the element type; the failures have the entry name in their field
+//path (e.g. "Labels[env]")
+func (v *Validator) applyEntryRules(fp fieldPlan, fc fieldContext, path string, failures *ValidationErrors) error {
+	//If there are no entries in the map, the rules are only checked for their existence
+	var entries []mapEntry
+	if fc.key != "" {
+		entries = findEntries(fc.m, fc.key)
+	}
+	if len(entries) == 0 {
+		err := v.applyRules(fp.keyRules, fieldContext{field: entryKeyField(fc.field)}, path, failures)
+		if err != nil {
+			return err
+		}
+		return v.applyRules(fp.valueRules, fieldContext{field: elementField(fc.field)}, path, failures)
+	}
+
+	for _, entry := range entries {
+		entryPath := fmt.Sprintf("%s[%s]", path, entry.name)
+		keyContext := fieldContext{key: entry.key, m: map[string]string{entry.key: entry.name},
+			field: entryKeyField(fc.field)}
+		err := v.applyRules(fp.keyRules, keyContext, entryPath, failures)
+		if err != nil {
+			return err
+		}
+		valueContext := fieldContext{key: entry.key, m: map[string]string{entry.key: fc.m[entry.key]},
+			lists: fc.lists, field: elementField(fc.field)}
+		err = v.applyRules(fp.valueRules, valueContext, entryPath, failures)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//Checks if the map value of a field (or each one of its elements for slice and array fields, or each one of its
+//entry values for map fields) is one of the values registered for the field type via RegisterEnum
+func (v *Validator) applyEnum(fc fieldContext, path string, failures *ValidationErrors) error {
+	if values, ok := v.lookupEnum(fieldType(fc.field).String()); ok {
+		return v.applyEnumValues(values, fc, path, failures)
+	}
+	elementValues, isElementEnum := v.lookupEnum(fieldType(elementField(fc.field)).String())
+	if isElementEnum && fieldType(fc.field).Kind() == reflect.Map {
+		for _, entry := range findEntries(fc.m, fc.key) {
+			entryContext := fieldContext{key: entry.key, m: map[string]string{entry.key: fc.m[entry.key]}}
+			err := v.applyEnumValues(elementValues, entryContext, fmt.Sprintf("%s[%s]", path, entry.name), failures)
+			if err != nil {
+				return err
+			}
 		}
+		return nil
+	}
+
+	mapValue, ok := fc.m[fc.key]
+	if !isSequence(fieldType(fc.field)) || !ok {
+		return nil
+	}
+	if values, ok := v.lookupEnum(fieldType(elementField(fc.field)).String()); ok {
+		for index, element := range fc.lists.split(fc.key, mapValue, fieldSeparator(fc.field)) {
+			elementContext := fieldContext{key: fc.key, m: map[string]string{fc.key: element}}
+			err := v.applyEnumValues(values, elementContext, fmt.Sprintf("%s[%d]", path, index), failures)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Checks if the map value of a field is one of the given enum values
+func (v *Validator) applyEnumValues(values []string, fc fieldContext, path string, failures *ValidationErrors) error {
+	err := checkEnum(fc.key, fc.m, values...)
+	if err != nil {
+		fieldErr := toFieldError(err, path, fc.key, ruleEnum, fc.m[fc.key], values)
+		return v.reportFailure(fieldErr, failures)
 	}
 	return nil
 }
 
+//Handles a failed rule based on the Validator configuration
+//If the Validator collects all the errors, the failure is stored inside "failures", otherwise it is returned
+func (v *Validator) reportFailure(fieldErr *FieldError, failures *ValidationErrors) error {
+	v.mutex.RLock()
+	collectAll := v.collectAll
+	v.mutex.RUnlock()
+	if !collectAll {
+		return fieldErr
+	}
+	*failures = append(*failures, fieldErr)
+
+	return nil
+}
+
 //Initializes the struct with the values form the map
 //If the provided struct contains several sub struct as fields, they too will be taken into account recursively
-func (v *Validator) initData(m map[string]string, t reflect.Value) error {
-	//Iterate over the list of struct fields
-	for index := 0; index < t.Type().NumField(); index++ {
+//Conversion failures are returned as FieldError values
+//The elements of the slice and array fields provided as separate values are found in "lists" (see elementLists)
+func (v *Validator) initData(m map[string]string, lists elementLists, t reflect.Value) error {
+	_, err := v.initStruct(m, lists, t, structPosition{parents: []reflect.Type{t.Type()}})
+	return err
+}
+
+//Initializes the struct found at "position" inside the initialized struct with the values from the map
+//Returns true if at least one of the fields (or of the fields of its sub structs) was initialized
+func (v *Validator) initStruct(m map[string]string, lists elementLists, t reflect.Value,
+	position structPosition) (bool, error) {
+	isSet := false
+	//Iterate over the list of struct fields, using the compiled plan of the struct type
+	for _, fp := range v.plan(t.Type()).fields {
 		//Get the current field from the struct
-		currField := t.Type().Field(index)
-		//If the current field is a sub struct, call the initialization function recursively
-		if currField.Type.Kind() == reflect.Struct {
-			err := v.initData(m, t.Field(index))
+		currField := fp.field
+		structFieldValue := t.Field(fp.index)
+		//If the current field is a sub struct or a pointer to a sub struct, call the initialization function
+		//recursively
+		//Nil pointers to sub structs are allocated only if at least one of the sub struct fields is initialized
+		if nestedType, ok := position.nested(currField); ok {
+			nested := structFieldValue
+			isNilPointer := currField.Type.Kind() == reflect.Ptr && structFieldValue.IsNil()
+			if isNilPointer {
+				nested = reflect.New(nestedType)
+			}
+			if currField.Type.Kind() == reflect.Ptr {
+				nested = nested.Elem()
+			}
+			isNestedSet, err := v.initStruct(m, lists, nested, position.child(currField, nestedType))
 			if err != nil {
-				return err
+				return isSet, err
+			}
+			if isNestedSet && isNilPointer {
+				structFieldValue.Set(nested.Addr())
 			}
+			isSet = isSet || isNestedSet
+		}
+
+		//If the current field is a slice of sub structs, initialize an element for each one found in the map
+		mapKey := position.key(currField)
+		if fp.isStructList && mapKey != "" {
+			isElementSet, err := v.initElements(m, lists, structFieldValue, fp.elementType,
+				position.with(currField, mapKey))
+			if err != nil {
+				return isSet, err
+			}
+			isSet = isSet || isElementSet
+		}
+
+		//If the current field is a map, initialize an entry for each one found in the map
+		if fp.isMap && mapKey != "" {
+			isEntrySet, err := v.initEntries(m, lists, structFieldValue, fp.separator,
+				position.with(currField, mapKey))
+			if err != nil {
+				return isSet, err
+			}
+			isSet = isSet || isEntrySet
 		}
 
 		//Get the map value associated with the current field via the "datakey" tag
-		if mapValue, ok := m[currField.Tag.Get(tagMapKey)]; ok {
-			structFieldValue := t.FieldByName(currField.Name)
-			//If the builtin data time is more complex (e.g. time.Time) it will build the type
-			//of the struct using the package path and the name of the type
-			structFieldType := structFieldValue.Type()
-			//Get the designated converter function for the current type from the "converterMappings" and call the
-			//converter function with the map value
-			//If the type is not mapped to a converter it will return an error
-			if converter, ok := v.converterMappings[structFieldType.String()]; ok {
-				result, err := converter(mapValue, structFieldType.String())
+		//The map fields and the slices of sub structs are only initialized from their prefixed keys, so a value
+		//provided under the key of the field itself is ignored, like it is by the rules of the field
+		if mapValue, ok := m[mapKey]; ok && mapKey != "" && !fp.isMap && !fp.isStructList {
+			//Convert the map value to the type of the field and set the result to the field
+			err := v.setField(structFieldValue, mapValue, lists[mapKey], fp.separator)
+			if err != nil {
+				path := fieldPath(position.path, currField.Name)
+				if fieldErr, ok := err.(*FieldError); ok {
+					fieldErr.Field = path + fieldErr.Field
+					return isSet, toFieldError(fieldErr, "", mapKey, "", mapValue, nil)
+				}
+				return isSet, errors.Wrapf(err, "error initializing field '%s'", path)
+			}
+			isSet = true
+		}
+	}
+	return isSet, nil
+}
+
+//Initializes a slice or array of sub structs with the elements found in the map under the key of the field
+//(e.g. "items[0].sku", "items.1.sku"); the element "n" of the map is stored at the index "n" of the slice, so the
+//indexes must start from 0 and have no gaps (see checkElementIndexes), unless the elements are pointers which are left
+//nil for the missing indexes
+//"position" is the position of the field (see structPosition.with)
+//Returns true if at least one element was found
+func (v *Validator) initElements(m map[string]string, lists elementLists, field reflect.Value,
+	elementType reflect.Type, position structPosition) (bool, error) {
+	elements := findElements(m, position.prefix)
+	if len(elements) == 0 {
+		return false, nil
+	}
+	if !field.CanSet() {
+		return false, fmt.Errorf("unexported field '%s' cannot be initialized", position.path)
+	}
+
+	fieldType := field.Type()
+	if fieldType.Elem().Kind() != reflect.Ptr {
+		if fieldErr := checkElementIndexes(elements, position.path, position.prefix); fieldErr != nil {
+			return false, fieldErr
+		}
+	}
+
+	//The elements are sorted by index, so the last one gives the length of the slice
+	last := elements[len(elements)-1]
+	sequence := reflect.New(fieldType).Elem()
+	switch {
+	case fieldType.Kind() == reflect.Array && last.index >= fieldType.Len():
+		return false, &FieldError{Field: fmt.Sprintf("%s[%d]", position.path, last.index), Key: last.prefix,
+			Err: fmt.Errorf("index out of range for an array of length %d", fieldType.Len())}
+	case fieldType.Kind() == reflect.Slice && last.index >= maxElements:
+		return false, &FieldError{Field: fmt.Sprintf("%s[%d]", position.path, last.index), Key: last.prefix,
+			Err: fmt.Errorf("index out of range, the maximum number of elements is %d", maxElements)}
+	case fieldType.Kind() == reflect.Slice:
+		sequence = reflect.MakeSlice(fieldType, last.index+1, last.index+1)
+	}
+
+	for _, element := range elements {
+		target := sequence.Index(element.index)
+		if fieldType.Elem().Kind() == reflect.Ptr {
+			target.Set(reflect.New(elementType))
+			target = target.Elem()
+		}
+		_, err := v.initStruct(m, lists, target, position.element(element, elementType))
+		if err != nil {
+			return false, err
+		}
+	}
+	field.Set(sequence)
+
+	return true, nil
+}
+
+//Initializes a map field with the entries found in the map under the key of the field (e.g. "label.env",
+//"label[env]"); the entry names are converted to the key type and the entry values to the element type
+//"position" is the position of the field (see structPosition.with)
+//Returns true if at least one entry was found
+func (v *Validator) initEntries(m map[string]string, lists elementLists, field reflect.Value, separator string,
+	position structPosition) (bool, error) {
+	entries := findEntries(m, position.prefix)
+	if len(entries) == 0 {
+		return false, nil
+	}
+	if !field.CanSet() {
+		return false, fmt.Errorf("unexported field '%s' cannot be initialized", position.path)
+	}
+
+	fieldType := field.Type()
+	result := reflect.MakeMapWithSize(fieldType, len(entries))
+	for _, entry := range entries {
+		path := fmt.Sprintf("%s[%s]", position.path, entry.name)
+		key, err := v.convertValue(entry.name, fieldType.Key())
+		if err != nil {
+			if fieldErr, ok := err.(*FieldError); ok {
+				return false, toFieldError(fieldErr, path, entry.key, "", entry.name, nil)
+			}
+			return false, errors.Wrapf(err, "error initializing field '%s'", path)
+		}
+
+		value := reflect.New(fieldType.Elem()).Elem()
+		err = v.setField(value, m[entry.key], lists[entry.key], separator)
+		if err != nil {
+			if fieldErr, ok := err.(*FieldError); ok {
+				fieldErr.Field = path + fieldErr.Field
+				return false, toFieldError(fieldErr, "", entry.key, "", m[entry.key], nil)
+			}
+			return false, errors.Wrapf(err, "error initializing field '%s'", path)
+		}
+		result.SetMapIndex(key, value)
+	}
+	field.Set(result)
+
+	return true, nil
+}
+
+//Converts a map value to the type of a field and sets the result to the field
+//The types that have a converter (see resolveConverter) are converted directly, otherwise:
+//* pointer fields (e.g. "*int", "*time.Time") are allocated and the map value is set to the pointed value;
+//  pointers to pointers are not supported
+//* slice and array fields (e.g. "[]string", "[3]int") are built from the elements of the map value, split using
+//  the separator, or from the "elements" provided as separate values (see elementLists) if they are not nil
+//The FieldError values returned for the elements of slices and arrays have their index as field path (e.g. "[1]")
+func (v *Validator) setField(field reflect.Value, value string, elements []string, separator string) error {
+	if !field.CanSet() {
+		return fmt.Errorf("unexported fields cannot be initialized")
+	}
+
+	fieldType := field.Type()
+	_, _, hasConverter := v.resolveConverter(fieldType)
+	switch {
+	case !hasConverter && fieldType.Kind() == reflect.Ptr:
+		if fieldType.Elem().Kind() == reflect.Ptr {
+			return fmt.Errorf("conversion to '%s' is not supported: pointers to pointers cannot be initialized",
+				fieldType)
+		}
+		pointer := reflect.New(fieldType.Elem())
+		err := v.setField(pointer.Elem(), value, elements, separator)
+		if err != nil {
+			return err
+		}
+		field.Set(pointer)
+	case !hasConverter && isSequence(fieldType):
+		if elements == nil {
+			elements = splitElements(value, separator)
+		}
+		sequence := reflect.New(fieldType).Elem()
+		if fieldType.Kind() == reflect.Slice {
+			sequence = reflect.MakeSlice(fieldType, len(elements), len(elements))
+		} else if len(elements) > fieldType.Len() {
+			return &FieldError{Value: value,
+				Err: fmt.Errorf("%d elements provided for an array of length %d", len(elements), fieldType.Len())}
+		}
+		for index, element := range elements {
+			err := v.setField(sequence.Index(index), element, nil, separator)
+			if err != nil {
+				if fieldErr, ok := err.(*FieldError); ok {
+					fieldErr.Field = fmt.Sprintf("[%d]%s", index, fieldErr.Field)
+				}
+				return err
+			}
+		}
+		field.Set(sequence)
+	default:
+		result, err := v.convertValue(value, fieldType)
+		if err != nil {
+			return err
+		}
+		field.Set(result)
+	}
+
+	return nil
+}
+
+//Finds the converter for a given type, trying in order:
+//* the converter registered under the type name (e.g. "time.Time", "primitive.ObjectID")
+//* the interface methods of the type if its pointer type implements encoding.TextUnmarshaler, flag.Value or
+//  sql.Scanner (e.g. "net.IP", "big.Int")
+//* the converter registered for the kind of the type (e.g. reflect.Int64 for "type UserID int64")
+//Besides the converter, it returns the type name that must be passed to it as param
+//The result is cached for each type until a converter is registered
+func (v *Validator) resolveConverter(t reflect.Type) (func(value string, params ...string) (interface{}, error),
+	string, bool) {
+	if resolved, ok := v.converterCache.Load(t); ok {
+		converter := resolved.(resolvedConverter)
+		return converter.fn, converter.typeName, converter.fn != nil
+	}
+
+	generation := v.registryGeneration()
+	converter, typeName, ok := v.findConverter(t)
+	v.storeCached(&v.converterCache, t, resolvedConverter{fn: converter, typeName: typeName}, generation)
+
+	return converter, typeName, ok
+}
+
+//Finds the converter for a given type in the registries of the Validator (see resolveConverter)
+func (v *Validator) findConverter(t reflect.Type) (func(value string, params ...string) (interface{}, error),
+	string, bool) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	if converter, ok := v.converterMappings[t.String()]; ok {
+		return converter, t.String(), true
+	}
+	if converter, ok := interfaceConverter(t); ok {
+		return converter, t.String(), true
+	}
+	if converter, ok := v.kindConverters[t.Kind()]; ok {
+		return converter, t.Kind().String(), true
+	}
+
+	return nil, "", false
+}
+
+//Returns the generation of the registries, incremented by each registration that invalidates the cached plans
+func (v *Validator) registryGeneration() uint64 {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+
+	return v.generation
+}
+
+//Returns the pattern registered under the given name via RegisterPattern
+func (v *Validator) lookupPattern(name string) (*regexp.Regexp, bool) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	pattern, ok := v.patterns[name]
+
+	return pattern, ok
+}
+
+//Returns the transformer registered under the given name (builtin or added via RegisterTransformer)
+func (v *Validator) lookupTransformer(name string) (func(value string, params ...string) (string, error), bool) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	transformer, ok := v.transformers[name]
+
+	return transformer, ok
+}
+
+//Returns the values registered for the given type name via RegisterEnum
+func (v *Validator) lookupEnum(typeName string) ([]string, bool) {
+	v.mutex.RLock()
+	defer v.mutex.RUnlock()
+	values, ok := v.enums[typeName]
+
+	return values, ok
+}
+
+//Calls "fn" for each field of the struct found at "position" and of its sub structs, with the plan of the field and
+//the position of the struct that contains the field; the map key of a field is given by position.key
+//The fields of the optional sub structs (pointers to sub structs) are visited only if at least one of the sub struct
+//keys is present, and the ones of the slices of sub structs only for the elements found in the map
+//The walk stops at the first error returned by "fn"
+func (v *Validator) walkFields(m map[string]string, t reflect.Type, position structPosition,
+	fn func(fp fieldPlan, position structPosition) error) error {
+	for _, fp := range v.plan(t).fields {
+		field := fp.field
+		if nestedType, ok := position.nested(field); ok {
+			nestedPosition := position.child(field, nestedType)
+			if field.Type.Kind() != reflect.Ptr || containsKeys(m, nestedType, nestedPosition) {
+				err := v.walkFields(m, nestedType, nestedPosition, fn)
 				if err != nil {
 					return err
 				}
+			}
+		}
 
-				//Set the computed value the field
-				structFieldValue.Set(reflect.ValueOf(result))
-			} else {
-				return fmt.Errorf("conversion to '%s' is not defined, please use RegisterConverter", structFieldType)
+		mapKey := position.key(field)
+		if fp.isStructList && mapKey != "" {
+			for _, element := range findElements(m, mapKey) {
+				elementPosition := position.with(field, mapKey).element(element, fp.elementType)
+				err := v.walkFields(m, fp.elementType, elementPosition, fn)
+				if err != nil {
+					return err
+				}
 			}
 		}
+
+		err := fn(fp, position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//Calls "fn" for each field of the struct type found at "position" and of its sub struct types, regardless of the map
+//data: unlike walkFields, the optional sub structs and the element types of the slices of sub structs are always
+//visited, the latter once, with "[]" as index (e.g. the field path "Items[].SKU" and the map key "items[].sku")
+//The walk stops at the first error returned by "fn"
+func (v *Validator) walkTypes(t reflect.Type, position structPosition,
+	fn func(fp fieldPlan, position structPosition) error) error {
+	for _, fp := range v.plan(t).fields {
+		field := fp.field
+		if nestedType, ok := position.nested(field); ok {
+			err := v.walkTypes(nestedType, position.child(field, nestedType), fn)
+			if err != nil {
+				return err
+			}
+		}
+		if fp.isStructList && !position.isParent(fp.elementType) {
+			elementPosition := structPosition{path: fieldPath(position.path, field.Name) + "[]",
+				parents: position.withParent(fp.elementType)}
+			if mapKey := position.key(field); mapKey != "" {
+				elementPosition.prefix = mapKey + "[]"
+			}
+			err := v.walkTypes(fp.elementType, elementPosition, fn)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := fn(fp, position)
+		if err != nil {
+			return err
+		}
 	}
+
 	return nil
 }
+
+//Checks if a field is an exported map (e.g. "Labels map[string]string") without a converter, whose entries are found
+//in the map under prefixed keys (e.g. "label.env")
+func (v *Validator) isMapField(field reflect.StructField) bool {
+	if field.PkgPath != "" || field.Type.Kind() != reflect.Map {
+		return false
+	}
+	_, _, ok := v.resolveConverter(field.Type)
+
+	return !ok
+}
+
+//Checks if a field is an exported slice or array of sub structs (e.g. "Items []Item", "Items []*Item"), whose
+//elements are found in the map under indexed keys (e.g. "items[0].sku")
+//Neither the field type nor the element type may have a converter (see resolveConverter)
+//Returns the type of the sub struct
+func (v *Validator) structElements(field reflect.StructField) (reflect.Type, bool) {
+	if field.PkgPath != "" || !isSequence(field.Type) {
+		return nil, false
+	}
+	if _, _, ok := v.resolveConverter(field.Type); ok {
+		return nil, false
+	}
+	elementType := field.Type.Elem()
+	if elementType.Kind() == reflect.Ptr {
+		elementType = elementType.Elem()
+	}
+	if elementType.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if _, _, ok := v.resolveConverter(elementType); ok {
+		return nil, false
+	}
+
+	return elementType, true
+}
+
+//Converts a map value to a value of the given type
+//Get the designated converter function for the type and call the converter function with the map value; the result
+//is converted to the given type if needed (e.g. from int64 to "type UserID int64")
+//Conversion failures are returned as FieldError values, while missing or misbehaving converters as plain errors
+func (v *Validator) convertValue(value string, t reflect.Type) (reflect.Value, error) {
+	converter, converterType, ok := v.resolveConverter(t)
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("conversion to '%s' is not defined, please use RegisterConverter", t)
+	}
+
+	result, err := converter(value, converterType)
+	if err != nil {
+		return reflect.Value{}, toFieldError(err, "", "", "", value, nil)
+	}
+	resultValue := reflect.ValueOf(result)
+	if !resultValue.IsValid() {
+		return reflect.Value{}, fmt.Errorf("converter for '%s' returned no value", converterType)
+	}
+	if resultValue.Type() != t {
+		if !resultValue.Type().ConvertibleTo(t) {
+			return reflect.Value{}, fmt.Errorf("converter for '%s' returned a value of type '%s'", converterType,
+				resultValue.Type())
+		}
+		resultValue = resultValue.Convert(t)
+	}
+
+	return resultValue, nil
+}
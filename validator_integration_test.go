@@ -1,9 +1,15 @@
 package validator_test
 
 import (
+	"database/sql"
 	"fmt"
 	"github.com/meltiseugen/validator"
+	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math/big"
+	"net"
+	"reflect"
+	"strconv"
 	"testing"
 	"time"
 )
@@ -131,4 +137,632 @@ func TestValidator_ValidateAndInit5(t *testing.T) {
 
 		t.Error()
 	}
-}
\ No newline at end of file
+}
+
+func TestValidator_ValidateAndInit6(t *testing.T) {
+	type InnerStruct struct {
+		C int `datakey:"c" validate:"required,int"`
+	}
+	type MyStruct struct {
+		A string `validate:"required" datakey:"a"`
+		B int    `validate:"int" datakey:"b"`
+		IS InnerStruct
+	}
+
+	m := map[string]string{
+		"b": "1",
+		"c": "qwer",
+	}
+
+	v := validator.New()
+	err := v.ValidateAndInit(m, &MyStruct{})
+	var fieldErr *validator.FieldError
+	if !errors.As(err, &fieldErr) {
+		t.Fatal()
+	}
+	if fieldErr.Field != "A" || fieldErr.Key != "a" || fieldErr.Rule != "required" {
+		t.Error()
+	}
+
+	v.SetCollectAllErrors(true)
+	err = v.ValidateAndInit(m, &MyStruct{})
+	var failures validator.ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 2 {
+		t.Fatal()
+	}
+	if failures[1].Field != "IS.C" || failures[1].Key != "c" || failures[1].Rule != "int" ||
+		failures[1].Value != "qwer" {
+		t.Error()
+	}
+}
+
+type Status string
+
+type Level int
+
+func TestValidator_ValidateAndInit7(t *testing.T) {
+	type MyStruct struct {
+		S  Status `datakey:"s" validate:"required"`
+		L  Level  `datakey:"l" validate:"int,oneof=1|2|3"`
+		C  string `datakey:"c" validate:"oneofci=red|green"`
+		S2 Status `datakey:"s2"`
+	}
+
+	testdata := []struct {
+		m           map[string]string
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{
+				"s": "active",
+				"l": "2",
+				"c": "Red",
+			},
+			true,
+		},
+		{
+			map[string]string{
+				"s": "unknown",
+				"l": "2",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"s":  "active",
+				"s2": "Active",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"s": "active",
+				"l": "4",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"s": "active",
+				"c": "blue",
+			},
+			false,
+		},
+	}
+
+	v := validator.New()
+	_ = v.RegisterEnum("validator_test.Status", "active", "inactive")
+	for i, td := range testdata {
+		t.Run("TestValidateAndInit7_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{}
+			err := v.ValidateAndInit(td.m, &s)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if td.noErrorFlag && (s.S != Status(td.m["s"]) || s.L != 2 || s.C != "Red") {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_ValidateAndInit8(t *testing.T) {
+	type MyStruct struct {
+		A int8    `datakey:"a"`
+		B uint16  `datakey:"b"`
+		C float32 `datakey:"c"`
+		D Level   `datakey:"d"`
+	}
+
+	s := MyStruct{}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"a": "-12", "b": "65535", "c": "1.25", "d": "3"}, &s)
+	if err != nil || s.A != -12 || s.B != 65535 || s.C != 1.25 || s.D != 3 {
+		t.Error()
+	}
+
+	err = v.ValidateAndInit(map[string]string{"a": "300"}, &MyStruct{})
+	var fieldErr *validator.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "A" || fieldErr.Value != "300" {
+		t.Error()
+	}
+
+	s = MyStruct{}
+	err = v.ValidateAndInit(map[string]string{"b": "-1"}, &s)
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "B" || s.B != 0 {
+		t.Error()
+	}
+}
+
+type UserID int64
+
+type Email string
+
+type Flag bool
+
+func TestValidator_ValidateAndInit9(t *testing.T) {
+	type MyStruct struct {
+		ID    UserID `datakey:"id" validate:"required,int"`
+		Email Email  `datakey:"email" validate:"email"`
+		Flag  Flag   `datakey:"flag"`
+	}
+
+	v := validator.New()
+	_ = v.RegisterKindConverter(reflect.Bool, func(value string, params ...string) (i interface{}, e error) {
+		return value == "yes", nil
+	})
+
+	s := MyStruct{}
+	err := v.ValidateAndInit(map[string]string{"id": "42", "email": "john@example.com", "flag": "yes"}, &s)
+	if err != nil || s.ID != 42 || s.Email != "john@example.com" || s.Flag != true {
+		t.Error()
+	}
+}
+
+func TestValidator_ValidateAndInit10(t *testing.T) {
+	type MyStruct struct {
+		IP     net.IP         `datakey:"ip" validate:"required,ip"`
+		Amount big.Int        `datakey:"amount"`
+		Note   sql.NullString `datakey:"note"`
+	}
+
+	s := MyStruct{}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"ip": "10.0.0.1", "amount": "1000000000000000000000", "note": "n"}, &s)
+	if err != nil || !s.IP.Equal(net.ParseIP("10.0.0.1")) || s.Amount.String() != "1000000000000000000000" ||
+		s.Note.String != "n" || !s.Note.Valid {
+		t.Error()
+	}
+
+	var fieldErr *validator.FieldError
+	err = v.ValidateAndInit(map[string]string{"ip": "10.0.0.1", "amount": "12a"}, &MyStruct{})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Amount" {
+		t.Error()
+	}
+}
+
+func TestValidator_ValidateAndInit11(t *testing.T) {
+	type Address struct {
+		City string `datakey:"city" validate:"required"`
+		Zip  int    `datakey:"zip" validate:"int"`
+	}
+	type Item struct {
+		SKU string `datakey:"sku" validate:"required"`
+		Qty int    `datakey:"qty" validate:"int,min=1"`
+	}
+	type Order struct {
+		Billing  Address  `datakey:"billing"`
+		Shipping *Address `datakey:"shipping"`
+		Items    []Item   `datakey:"items" validate:"required,minlen=2"`
+		Extra    []*Item  `datakey:"extra"`
+	}
+
+	s := Order{}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{
+		"billing.city":  "Cluj",
+		"billing.zip":   "400000",
+		"items[0].sku":  "A1",
+		"items[0].qty":  "2",
+		"items.1.sku":   "B2",
+		"extra[1].sku":  "C3",
+		"shipping.city": "Oradea",
+	}, &s)
+	if err != nil || s.Billing.City != "Cluj" || s.Billing.Zip != 400000 || s.Shipping == nil ||
+		s.Shipping.City != "Oradea" || len(s.Items) != 2 || s.Items[0].SKU != "A1" || s.Items[0].Qty != 2 ||
+		s.Items[1].SKU != "B2" || len(s.Extra) != 2 || s.Extra[0] != nil || s.Extra[1].SKU != "C3" {
+		t.Error()
+	}
+
+	var fieldErr *validator.FieldError
+	err = v.ValidateAndInit(map[string]string{
+		"billing.city": "Cluj",
+		"items[0].sku": "A1",
+		"items[1].sku": "B2",
+		"items[1].qty": "0",
+	}, &Order{})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Items[1].Qty" || fieldErr.Key != "items[1].qty" {
+		t.Error()
+	}
+
+	err = v.ValidateAndInit(map[string]string{"billing.city": "Cluj", "items[0].sku": "A1"}, &Order{})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Items" || fieldErr.Rule != "minlen" {
+		t.Error()
+	}
+
+	err = v.ValidateAndInit(map[string]string{"city": "Cluj", "items[0].sku": "A1", "items[1].sku": "B2"}, &Order{})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Billing.City" || fieldErr.Key != "billing.city" {
+		t.Error()
+	}
+
+	err = v.ValidateAndInit(map[string]string{"billing.city": "Cluj", "items[0].sku": "A1", "items[1].sku": "B2",
+		"extra[10000].sku": "C3"}, &Order{})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "Extra[10000]" {
+		t.Error()
+	}
+}
+
+func TestValidator_ValidateAndInit12(t *testing.T) {
+	type MyStruct struct {
+		Labels map[string]string `datakey:"label" validate:"maxlen=3,dive,keys,minlen=2,endkeys,required"`
+		Limits map[string]int    `datakey:"limit" validate:"dive,int,min=0"`
+		Ports  map[int][]int     `datakey:"ports"`
+		Levels map[string]Level  `datakey:"level"`
+	}
+
+	s := MyStruct{}
+	v := validator.New()
+	_ = v.RegisterEnum("validator_test.Level", "1", "2")
+	err := v.ValidateAndInit(map[string]string{
+		"label.env":   "prod",
+		"label[team]": "core",
+		"limit.cpu":   "2",
+		"ports[80]":   "8080,8081",
+		"level.debug": "2",
+	}, &s)
+	if err != nil || !reflect.DeepEqual(s.Labels, map[string]string{"env": "prod", "team": "core"}) ||
+		!reflect.DeepEqual(s.Limits, map[string]int{"cpu": 2}) ||
+		!reflect.DeepEqual(s.Ports, map[int][]int{80: {8080, 8081}}) || s.Levels["debug"] != 2 {
+		t.Error()
+	}
+
+	//The value provided under the key of a map field itself is not an entry of the map
+	s = MyStruct{}
+	err = v.ValidateAndInit(map[string]string{"label": "x", "label.env": "prod", "ports": "80"}, &s)
+	if err != nil || !reflect.DeepEqual(s.Labels, map[string]string{"env": "prod"}) || s.Ports != nil {
+		t.Error(err)
+	}
+
+	testdata := []struct {
+		m     map[string]string
+		field string
+		rule  string
+	}{
+		{map[string]string{"label.e": "prod"}, "Labels[e]", "minlen"},
+		{map[string]string{"label.a1": "1", "label.a2": "2", "label.a3": "3", "label.a4": "4"}, "Labels", "maxlen"},
+		{map[string]string{"limit.cpu": "-1"}, "Limits[cpu]", "min"},
+		{map[string]string{"limit.cpu": "x"}, "Limits[cpu]", "int"},
+		{map[string]string{"level.debug": "3"}, "Levels[debug]", "enum"},
+		{map[string]string{"ports.http": "80"}, "Ports[http]", ""},
+	}
+	for i, td := range testdata {
+		t.Run("TestValidateAndInit12_"+strconv.Itoa(i), func(t *testing.T) {
+			var fieldErr *validator.FieldError
+			err := v.ValidateAndInit(td.m, &MyStruct{})
+			if !errors.As(err, &fieldErr) || fieldErr.Field != td.field || fieldErr.Rule != td.rule {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_ValidateAndInit13(t *testing.T) {
+	type MyStruct struct {
+		Timeout time.Duration `datakey:"timeout" validate:"required" default:"30s"`
+		Start   time.Time     `datakey:"start" validate:"time" default:"2019-01-01T00:00:00Z"`
+		Retries int           `datakey:"retries" validate:"int,max=5" default:"3"`
+	}
+
+	s := MyStruct{}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"retries": "1"}, &s)
+	if err != nil || s.Timeout != 30*time.Second || s.Retries != 1 ||
+		!s.Start.Equal(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Error()
+	}
+
+	type Invalid struct {
+		Timeout time.Duration `datakey:"timeout" default:"30"`
+	}
+	if err := v.ValidateAndInit(map[string]string{"timeout": "1s"}, &Invalid{}); err == nil {
+		t.Error()
+	}
+	_ = v.RegisterConverter("time.Duration", func(value string, params ...string) (interface{}, error) {
+		seconds, err := strconv.Atoi(value)
+		return time.Duration(seconds) * time.Second, err
+	})
+	invalid := Invalid{}
+	if err := v.ValidateAndInit(map[string]string{}, &invalid); err != nil || invalid.Timeout != 30*time.Second {
+		t.Error()
+	}
+}
+
+func TestValidator_ValidateAndInit14(t *testing.T) {
+	type MyStruct struct {
+		ID     int               `datakey:"id" validate:"required,int" transform:"trim"`
+		Active bool              `datakey:"active" validate:"bool" transform:"trim,lower"`
+		Name   string            `datakey:"name" validate:"maxlen=8" transform:"collapse"`
+		Tags   []string          `datakey:"tags" validate:"dive,oneof=a|b" transform:"trim"`
+		Env    map[string]string `datakey:"env" validate:"dive,oneof=DEV|PROD" transform:"upper"`
+	}
+
+	s := MyStruct{}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"id": " 1 ", "active": " TRUE", "name": "  John   Doe ",
+		"tags": " a, b", "env[app]": "prod"}, &s)
+	if err != nil || s.ID != 1 || !s.Active || s.Name != "John Doe" ||
+		!reflect.DeepEqual(s.Tags, []string{"a", "b"}) || s.Env["app"] != "PROD" {
+		t.Error()
+	}
+
+	_ = v.RegisterTransformer("fail", func(value string, params ...string) (string, error) {
+		return "", fmt.Errorf("failed")
+	})
+	type Failing struct {
+		A string `datakey:"a" transform:"fail"`
+	}
+	var fieldErr *validator.FieldError
+	err = v.ValidateAndInit(map[string]string{"a": "x"}, &Failing{})
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "A" || fieldErr.Rule != "fail" || fieldErr.Value != "x" {
+		t.Error()
+	}
+}
+
+func TestValidator_ValidateAndInit15(t *testing.T) {
+	type Address struct {
+		Country string `datakey:"country"`
+		State   string `datakey:"state" validate:"required_if=country|US"`
+	}
+	type MyStruct struct {
+		Type    string  `datakey:"type" validate:"required"`
+		Company string  `datakey:"company" validate:"required_if=type|business,excluded_if=type|personal"`
+		Email   string  `datakey:"email" validate:"required_without=phone"`
+		Phone   string  `datakey:"phone"`
+		Billing Address `datakey:"billing"`
+	}
+
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"type": "business", "company": "ACME", "phone": "123",
+		"billing.country": "US", "billing.state": "NY"}, &MyStruct{})
+	if err != nil {
+		t.Error()
+	}
+
+	v.SetCollectAllErrors(true)
+	err = v.ValidateAndInit(map[string]string{"type": "personal", "company": "ACME", "billing.country": "US"},
+		&MyStruct{})
+	var failures validator.ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 3 {
+		t.Fatal()
+	}
+	if failures[0].Field != "Company" || failures[0].Rule != "excluded_if" || failures[0].Trigger != "type" ||
+		failures[1].Field != "Email" || failures[1].Trigger != "phone" ||
+		failures[2].Field != "Billing.State" || failures[2].Key != "billing.state" ||
+		failures[2].Trigger != "billing.country" {
+		t.Error()
+	}
+}
+
+func TestValidator_ValidateAndInit16(t *testing.T) {
+	type Period struct {
+		Start time.Time `datakey:"start" validate:"required,time"`
+		End   time.Time `datakey:"end" validate:"required,time,gtfield=start"`
+	}
+	type MyStruct struct {
+		Password string `datakey:"password" validate:"required"`
+		Confirm  string `datakey:"confirm" validate:"required,eqfield=password"`
+		Min      int    `datakey:"min" validate:"int"`
+		Max      int    `datakey:"max" validate:"int,gtfield=min"`
+		Period   Period `datakey:"period"`
+	}
+
+	s := MyStruct{}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"password": "secret", "confirm": "secret", "min": "1", "max": "5",
+		"period.start": "2019-08-21T09:00:00Z", "period.end": "2019-08-22T09:00:00Z"}, &s)
+	if err != nil || s.Confirm != "secret" || s.Max != 5 || !s.Period.End.After(s.Period.Start) {
+		t.Error()
+	}
+
+	v.SetCollectAllErrors(true)
+	s = MyStruct{}
+	err = v.ValidateAndInit(map[string]string{"password": "secret", "confirm": "other", "min": "5", "max": "5",
+		"period.start": "2019-08-21T09:00:00Z", "period.end": "2019-08-20T09:00:00Z"}, &s)
+	var failures validator.ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 3 || s.Password != "" {
+		t.Fatal()
+	}
+	if failures[0].Field != "Confirm" || failures[0].Rule != "eqfield" || failures[1].Field != "Max" ||
+		failures[2].Field != "Period.End" || failures[2].Key != "period.end" {
+		t.Error()
+	}
+
+	_ = v.RegisterValueRule("future", func(value reflect.Value, lookup validator.FieldLookup, params ...string) error {
+		if !value.Interface().(time.Time).After(time.Now()) {
+			return fmt.Errorf("time is not in the future")
+		}
+		return nil
+	})
+	type Event struct {
+		At time.Time `datakey:"at" validate:"time,future"`
+	}
+	err = v.ValidateAndInit(map[string]string{"at": "2019-08-21T09:00:00Z"}, &Event{})
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].Rule != "future" {
+		t.Error()
+	}
+}
+
+type Period struct {
+	Start time.Time `datakey:"start" validate:"required,time"`
+	End   time.Time `datakey:"end" validate:"required,time"`
+}
+
+func (p *Period) Validate() error {
+	if p.End.Sub(p.Start) > 24*time.Hour {
+		return &validator.FieldError{Field: "End", Key: "end", Err: fmt.Errorf("period too long")}
+	}
+	return nil
+}
+
+type Booking struct {
+	Room    int      `datakey:"room" validate:"required,int"`
+	Period  Period   `datakey:"period"`
+	Extra   []Period `datakey:"extra"`
+	checked *[]string
+}
+
+func (b Booking) ValidateWith(v *validator.Validator) error {
+	*b.checked = append(*b.checked, "booking")
+	if b.Room == 13 {
+		return fmt.Errorf("room not available")
+	}
+	return nil
+}
+
+func TestValidator_ValidateAndInit17(t *testing.T) {
+	checked := []string{}
+	s := Booking{checked: &checked}
+	v := validator.New()
+	err := v.ValidateAndInit(map[string]string{"room": "1", "period.start": "2019-08-21T09:00:00Z",
+		"period.end": "2019-08-21T19:00:00Z"}, &s)
+	if err != nil || s.Room != 1 || len(checked) != 1 {
+		t.Error()
+	}
+
+	var fieldErr *validator.FieldError
+	s = Booking{checked: &checked}
+	err = v.ValidateAndInit(map[string]string{"room": "13", "period.start": "2019-08-21T09:00:00Z",
+		"period.end": "2019-08-21T19:00:00Z"}, &s)
+	if !errors.As(err, &fieldErr) || fieldErr.Field != "" || fieldErr.Rule != "validate" || s.Room != 0 {
+		t.Error()
+	}
+
+	v.SetCollectAllErrors(true)
+	err = v.ValidateAndInit(map[string]string{"room": "13", "period.start": "2019-08-21T09:00:00Z",
+		"period.end": "2019-08-23T09:00:00Z", "extra[0].start": "2019-08-21T09:00:00Z",
+		"extra[0].end": "2019-08-21T10:00:00Z", "extra[1].start": "2019-08-21T09:00:00Z",
+		"extra[1].end": "2019-08-25T09:00:00Z"}, &Booking{checked: &checked})
+	var failures validator.ValidationErrors
+	if !errors.As(err, &failures) || len(failures) != 3 {
+		t.Fatal()
+	}
+	if failures[0].Field != "Period.End" || failures[0].Key != "period.end" ||
+		failures[1].Field != "Extra[1].End" || failures[1].Key != "extra[1].end" ||
+		failures[2].Field != "" || failures[2].Err.Error() != "room not available" {
+		t.Error()
+	}
+}
+
+type Audit struct {
+	Author string `datakey:"author"`
+}
+
+func (a *Audit) Validate() error {
+	if a.Author == "" {
+		return fmt.Errorf("author is missing")
+	}
+	return nil
+}
+
+type Article struct {
+	Audit
+	Title string `datakey:"title" validate:"required"`
+}
+
+type Review struct {
+	Audit
+	Score int `datakey:"score" validate:"required"`
+}
+
+func (r Review) Validate() error {
+	if r.Score == 1 {
+		return fmt.Errorf("score too low")
+	}
+	return r.Audit.Validate()
+}
+
+type Comment struct {
+	Audit
+	Text string `datakey:"text"`
+}
+
+func (c *Comment) ValidateWith(v *validator.Validator) error {
+	if c.Text == "" {
+		return fmt.Errorf("text is missing")
+	}
+	return nil
+}
+
+func TestValidator_ValidateAndInit18(t *testing.T) {
+	v := validator.New()
+	v.SetCollectAllErrors(true)
+
+	//The method promoted from the embedded struct is called once, on the struct that embeds it
+	var failures validator.ValidationErrors
+	err := v.ValidateAndInit(map[string]string{"title": "Go"}, &Article{})
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].Field != "" ||
+		failures[0].Err.Error() != "author is missing" {
+		t.Error(err)
+	}
+	err = v.ValidateStruct(Article{Title: "Go"})
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].Field != "" {
+		t.Error(err)
+	}
+	if err := v.ValidateStruct(Article{Audit: Audit{Author: "John"}, Title: "Go"}); err != nil {
+		t.Error(err)
+	}
+
+	//A method declared by the struct replaces the one of the embedded struct, which it calls itself
+	err = v.ValidateAndInit(map[string]string{"score": "1"}, &Review{})
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].Err.Error() != "score too low" {
+		t.Error(err)
+	}
+	err = v.ValidateAndInit(map[string]string{"score": "2"}, &Review{})
+	if !errors.As(err, &failures) || len(failures) != 1 || failures[0].Err.Error() != "author is missing" {
+		t.Error(err)
+	}
+
+	//A different hook method is called on each struct
+	err = v.ValidateAndInit(map[string]string{}, &Comment{})
+	if !errors.As(err, &failures) || len(failures) != 2 || failures[0].Field != "Audit" ||
+		failures[1].Err.Error() != "text is missing" {
+		t.Error(err)
+	}
+}
+
+func TestValidator_ValidateAndInit19(t *testing.T) {
+	type Item struct {
+		City string `datakey:"city" validate:"required"`
+	}
+	type MyStruct struct {
+		Items []Item  `datakey:"items"`
+		Extra []*Item `datakey:"extra"`
+	}
+
+	testData := []struct {
+		in          map[string]string
+		field       string
+		key         string
+		noErrorFlag bool
+	}{
+		{map[string]string{"items[0].city": "X", "items.1.city": "Y"}, "", "", true},
+		{map[string]string{"items[0].city": "X", "items.2.city": "Y"}, "Items[1]", "items[1]", false},
+		{map[string]string{"items[5000].city": "X"}, "Items[0]", "items[0]", false},
+		{map[string]string{"extra[1].city": "X"}, "", "", true},
+		{map[string]string{"items": "x", "items[0].city": "X", "extra": "x"}, "", "", true},
+	}
+
+	v := validator.New()
+	for i, td := range testData {
+		t.Run("TestValidator_ValidateAndInit19_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{}
+			err := v.ValidateAndInit(td.in, &s)
+			if td.noErrorFlag && err != nil {
+				t.Error(err)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+
+			var fieldErr *validator.FieldError
+			if !td.noErrorFlag && (!errors.As(err, &fieldErr) || fieldErr.Field != td.field || fieldErr.Key != td.key ||
+				s.Items != nil) {
+				t.Error(err)
+			}
+			if err := v.Init(td.in, &MyStruct{}); (err == nil) != td.noErrorFlag {
+				t.Error(err)
+			}
+		})
+	}
+}
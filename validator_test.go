@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestValidator_New(t *testing.T) {
@@ -12,10 +14,10 @@ func TestValidator_New(t *testing.T) {
 	if v == nil {
 		t.Error()
 	} else {
-		if len(v.converterMappings) != 4 {
+		if len(v.converterMappings) != 17 {
 			t.Error()
 		}
-		if len(v.ruleMappings) != 5 {
+		if len(v.ruleMappings) != 23 {
 			t.Error()
 		}
 		if v.isInit != true {
@@ -36,10 +38,10 @@ func TestValidator_GetInstance(t *testing.T) {
 	if v == nil {
 		t.Error()
 	} else {
-		if len(v.converterMappings) != 4 {
+		if len(v.converterMappings) != 17 {
 			t.Error()
 		}
-		if len(v.ruleMappings) != 5 {
+		if len(v.ruleMappings) != 23 {
 			t.Error()
 		}
 		if v.isInit != true {
@@ -160,7 +162,7 @@ func TestValidator_checkRules(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.checkRules(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -204,7 +206,7 @@ func TestValidator_checkRules2(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.checkRules(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -235,7 +237,7 @@ func TestValidator_checkRules3(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestValidator_checkRules3_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.checkRules(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -267,7 +269,7 @@ func TestValidator_checkRules4(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.checkRules(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -318,7 +320,7 @@ func TestValidator_initData(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.initData(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.initData(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -362,7 +364,7 @@ func TestValidator_initData2(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.initData(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.initData(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -393,7 +395,7 @@ func TestValidator_initData3(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.initData(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.initData(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				t.Error()
 			} else if !td.noErrorFlag && err == nil {
@@ -429,7 +431,7 @@ func TestValidator_initData4(t *testing.T) {
 	v := New()
 	for i, td := range testdata {
 		t.Run("TestCheckTime_"+strconv.Itoa(i), func(t *testing.T) {
-			err := v.initData(td.m, reflect.ValueOf(&MyStruct{}).Elem())
+			err := v.initData(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
 			if td.noErrorFlag && err != nil {
 				fmt.Println(err)
 				t.Error()
@@ -565,4 +567,871 @@ func TestValidator_ValidateAndInit4(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestValidator_checkRules5(t *testing.T) {
+	type MyStruct struct {
+		A string `validate:"required,between=1|10" datakey:"a"`
+		B string `validate:"between=a\\|b|c" datakey:"b"`
+	}
+
+	var params [][]string
+	v := New()
+	_ = v.RegisterRule("between", func(mapKey string, m map[string]string, params_ ...string) error {
+		params = append(params, params_)
+		return nil
+	})
+
+	err := v.checkRules(map[string]string{"a": "1", "b": "2"}, nil, reflect.ValueOf(&MyStruct{}).Elem())
+	if err != nil {
+		t.Error()
+	}
+	if !reflect.DeepEqual(params, [][]string{{"1", "10"}, {"a|b", "c"}}) {
+		t.Error()
+	}
+}
+
+func TestValidator_checkRules6(t *testing.T) {
+	type MyStruct struct {
+		A string `validate:"required,=10" datakey:"a"`
+	}
+
+	v := New()
+	err := v.checkRules(map[string]string{"a": "1"}, nil, reflect.ValueOf(&MyStruct{}).Elem())
+	if err == nil {
+		t.Error()
+	}
+}
+
+func TestValidator_SetCollectAllErrors(t *testing.T) {
+	type InnerStruct struct {
+		C int `validate:"required,int" datakey:"c"`
+	}
+	type MyStruct struct {
+		A string `validate:"required" datakey:"a"`
+		B int    `validate:"int" datakey:"b"`
+		IS InnerStruct
+	}
+	testdata := []struct {
+		m          map[string]string
+		collectAll bool
+		failures   int
+	}{
+		{
+			map[string]string{
+				"b": "asdf",
+				"c": "qwer",
+			},
+			true,
+			3,
+		},
+		{
+			map[string]string{
+				"a": "123",
+				"b": "1",
+			},
+			true,
+			1,
+		},
+		{
+			map[string]string{
+				"a": "123",
+				"b": "1",
+				"c": "1",
+			},
+			true,
+			0,
+		},
+		{
+			map[string]string{
+				"b": "asdf",
+				"c": "qwer",
+			},
+			false,
+			1,
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestSetCollectAllErrors_"+strconv.Itoa(i), func(t *testing.T) {
+			v := New()
+			v.SetCollectAllErrors(td.collectAll)
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
+			if td.failures == 0 && err != nil {
+				t.Error()
+			}
+			if td.failures > 0 && err == nil {
+				t.Error()
+			}
+			if failures, ok := err.(ValidationErrors); ok && len(failures) != td.failures {
+				t.Error()
+			} else if !ok && td.collectAll && td.failures > 0 {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_ValidateAndInit5(t *testing.T) {
+	type MyStruct struct {
+		A string `validate:"required" datakey:"a"`
+		B int    `validate:"int" datakey:"b"`
+		C int    `validate:"int" datakey:"c"`
+	}
+	m := map[string]string{
+		"a": "asdf",
+		"b": "1",
+		"c": "qwer",
+	}
+
+	s := MyStruct{}
+	v := New()
+	v.SetCollectAllErrors(true)
+
+	err := v.ValidateAndInit(m, &s)
+	if err == nil {
+		t.Error()
+	}
+	if s.A != "" || s.B != 0 {
+		t.Error()
+	}
+}
+
+func TestValidator_checkRules7(t *testing.T) {
+	type MyStruct struct {
+		A string `validate:"required,minlen=3,maxlen=5" datakey:"a"`
+		B int    `validate:"int,between=1|10" datakey:"b"`
+	}
+	testdata := []struct {
+		m           map[string]string
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{
+				"a": "qwer",
+				"b": "10",
+			},
+			true,
+		},
+		{
+			map[string]string{
+				"a": "qw",
+				"b": "1",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"a": "qwe",
+				"b": "11",
+			},
+			false,
+		},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestValidator_checkRules7_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_RegisterPattern(t *testing.T) {
+	v := Validator{}
+	if v.RegisterPattern("sku", "^[A-Z]+$") == nil {
+		t.Error()
+	}
+
+	v1 := New()
+	if v1.RegisterPattern("", "^[A-Z]+$") == nil {
+		t.Error()
+	}
+	if v1.RegisterPattern("sku", "^[A-Z+$") == nil {
+		t.Error()
+	}
+	if v1.RegisterPattern("sku", "^[A-Z]+$") != nil {
+		t.Error()
+	}
+	if _, ok := v1.patterns["sku"]; !ok {
+		t.Error()
+	}
+}
+
+func TestValidator_checkRules8(t *testing.T) {
+	type MyStruct struct {
+		A string `validate:"required,pattern=sku" datakey:"a"`
+		B string `validate:"regex=^(x|y)[0-9]{1\\,2}$" datakey:"b"`
+	}
+	testdata := []struct {
+		m           map[string]string
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{
+				"a": "ABC",
+				"b": "x12",
+			},
+			true,
+		},
+		{
+			map[string]string{
+				"a": "ABC",
+				"b": "z12",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"a": "abc",
+				"b": "y1",
+			},
+			false,
+		},
+	}
+
+	v := New()
+	_ = v.RegisterPattern("sku", "^[A-Z]+$")
+	for i, td := range testdata {
+		t.Run("TestValidator_checkRules8_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_RegisterEnum(t *testing.T) {
+	v := Validator{}
+	if v.RegisterEnum("validator.Status", "active") == nil {
+		t.Error()
+	}
+
+	v1 := New()
+	if v1.RegisterEnum("", "active") == nil {
+		t.Error()
+	}
+	if v1.RegisterEnum("validator.Status") == nil {
+		t.Error()
+	}
+	if v1.RegisterEnum("validator.Status", "active", "inactive") != nil {
+		t.Error()
+	}
+	if len(v1.enums["validator.Status"]) != 2 {
+		t.Error()
+	}
+}
+
+func TestValidator_RegisterKindConverter(t *testing.T) {
+	v := Validator{}
+	err := v.RegisterKindConverter(reflect.Int, func(value string, params ...string) (i interface{}, e error) {
+		return nil, nil
+	})
+	if err == nil {
+		t.Error()
+	}
+
+	v1 := New()
+	err = v1.RegisterKindConverter(reflect.Invalid, func(value string, params ...string) (i interface{}, e error) {
+		return nil, nil
+	})
+	if err == nil {
+		t.Error()
+	}
+	err = v1.RegisterKindConverter(reflect.Complex128, func(value string, params ...string) (i interface{}, e error) {
+		return nil, nil
+	})
+	if err != nil {
+		t.Error()
+	}
+	if _, ok := v1.kindConverters[reflect.Complex128]; !ok {
+		t.Error()
+	}
+}
+
+func TestValidator_resolveConverter(t *testing.T) {
+	type UserID int64
+	type Email string
+	type Point struct {
+		X int
+	}
+	testdata := []struct {
+		in            reflect.Type
+		converterType string
+		ok            bool
+	}{
+		{reflect.TypeOf(0), "int", true},
+		{reflect.TypeOf(time.Time{}), "time.Time", true},
+		{reflect.TypeOf(UserID(0)), "int64", true},
+		{reflect.TypeOf(Email("")), "string", true},
+		{reflect.TypeOf(Point{}), "", false},
+		{reflect.TypeOf(complex64(0)), "", false},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestResolveConverter_"+strconv.Itoa(i), func(t *testing.T) {
+			_, converterType, ok := v.resolveConverter(td.in)
+			if ok != td.ok || converterType != td.converterType {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_convertValue(t *testing.T) {
+	type UserID int64
+	v := New()
+	_ = v.RegisterConverter("validator.UserID", func(value string, params ...string) (i interface{}, e error) {
+		return nil, nil
+	})
+	if _, err := v.convertValue("1", reflect.TypeOf(UserID(0))); err == nil {
+		t.Error()
+	}
+	_ = v.RegisterConverter("validator.UserID", func(value string, params ...string) (i interface{}, e error) {
+		return "1", nil
+	})
+	if _, err := v.convertValue("1", reflect.TypeOf(UserID(0))); err == nil {
+		t.Error()
+	}
+	if _, err := v.convertValue("a", reflect.TypeOf(0)); err == nil {
+		t.Error()
+	}
+	result, err := v.convertValue("12", reflect.TypeOf(int16(0)))
+	if err != nil || result.Interface() != int16(12) {
+		t.Error()
+	}
+}
+
+func TestValidator_initData5(t *testing.T) {
+	type InnerStruct struct {
+		C *int `validate:"int" datakey:"c"`
+		D string `datakey:"d"`
+	}
+	type MyStruct struct {
+		A  *int       `validate:"int" datakey:"a"`
+		T  *time.Time `validate:"time" datakey:"t"`
+		IS *InnerStruct
+	}
+	testdata := []struct {
+		m          map[string]string
+		isANil     bool
+		isTNil     bool
+		isInnerNil bool
+	}{
+		{
+			map[string]string{
+				"a": "0",
+				"t": "2019-08-21T09:00:00Z",
+				"c": "1",
+			},
+			false,
+			false,
+			false,
+		},
+		{
+			map[string]string{
+				"d": "qwerty",
+			},
+			true,
+			true,
+			false,
+		},
+		{
+			map[string]string{},
+			true,
+			true,
+			true,
+		},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestValidator_initData5_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{}
+			err := v.initData(td.m, nil, reflect.ValueOf(&s).Elem())
+			if err != nil {
+				t.Fatal()
+			}
+			if (s.A == nil) != td.isANil || (s.T == nil) != td.isTNil || (s.IS == nil) != td.isInnerNil {
+				t.Error()
+			}
+			if !td.isANil && *s.A != 0 {
+				t.Error()
+			}
+			if !td.isInnerNil && s.IS.D != td.m["d"] {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_initData6(t *testing.T) {
+	type Node struct {
+		Value int `datakey:"value"`
+		Next  *Node
+	}
+	type MyStruct struct {
+		A **int `datakey:"a"`
+		b int   `datakey:"b"`
+		N Node
+	}
+	testdata := []struct {
+		m           map[string]string
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{
+				"value": "1",
+			},
+			true,
+		},
+		{
+			map[string]string{
+				"a": "1",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"b": "1",
+			},
+			false,
+		},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestValidator_initData6_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{}
+			err := v.initData(td.m, nil, reflect.ValueOf(&s).Elem())
+			if td.noErrorFlag && (err != nil || s.N.Value != 1 || s.N.Next != nil || s.b != 0) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_checkRules9(t *testing.T) {
+	type InnerStruct struct {
+		C int    `validate:"required,int" datakey:"c"`
+		D string `datakey:"d"`
+	}
+	type MyStruct struct {
+		A  *int `validate:"int" datakey:"a"`
+		IS *InnerStruct
+	}
+	testdata := []struct {
+		m           map[string]string
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{
+				"a": "1",
+			},
+			true,
+		},
+		{
+			map[string]string{
+				"d": "qwerty",
+			},
+			false,
+		},
+		{
+			map[string]string{
+				"c": "1",
+				"d": "qwerty",
+			},
+			true,
+		},
+		{
+			map[string]string{
+				"a": "qwerty",
+			},
+			false,
+		},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestValidator_checkRules9_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_initData7(t *testing.T) {
+	type MyStruct struct {
+		Tags   []string     `datakey:"tags"`
+		IDs    []int        `datakey:"ids" datasep:";"`
+		Fixed  [3]int       `datakey:"fixed"`
+		Times  []*time.Time `datakey:"times"`
+		Levels []uint8      `datakey:"levels"`
+	}
+	testdata := []struct {
+		m           map[string]string
+		out         MyStruct
+		noErrorFlag bool
+	}{
+		{
+			map[string]string{
+				"tags":  "a,b,c",
+				"ids":   "1;2;3",
+				"fixed": "1,2",
+			},
+			MyStruct{Tags: []string{"a", "b", "c"}, IDs: []int{1, 2, 3}, Fixed: [3]int{1, 2, 0}},
+			true,
+		},
+		{
+			map[string]string{
+				"tags": "",
+			},
+			MyStruct{Tags: []string{}},
+			true,
+		},
+		{
+			map[string]string{
+				"ids": "1,2",
+			},
+			MyStruct{},
+			false,
+		},
+		{
+			map[string]string{
+				"fixed": "1,2,3,4",
+			},
+			MyStruct{},
+			false,
+		},
+		{
+			map[string]string{
+				"levels": "1,256",
+			},
+			MyStruct{},
+			false,
+		},
+	}
+
+	v := New()
+	for i, td := range testdata {
+		t.Run("TestValidator_initData7_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{}
+			err := v.initData(td.m, nil, reflect.ValueOf(&s).Elem())
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(s, td.out)) {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+
+	s := MyStruct{}
+	err := v.initData(map[string]string{"times": "2019-08-21T09:00:00Z,2019-08-22T09:00:00Z"}, nil,
+		reflect.ValueOf(&s).Elem())
+	if err != nil || len(s.Times) != 2 || s.Times[1].Day() != 22 {
+		t.Error()
+	}
+	err = v.initData(map[string]string{"levels": "1,256"}, nil, reflect.ValueOf(&s).Elem())
+	if fieldErr, ok := err.(*FieldError); !ok || fieldErr.Field != "Levels[1]" || fieldErr.Value != "256" ||
+		fieldErr.Key != "levels" {
+		t.Error()
+	}
+}
+
+func TestValidator_checkRules10(t *testing.T) {
+	type MyStruct struct {
+		Tags []string `datakey:"tags" validate:"maxlen=3,dive,minlen=2"`
+		IDs  []int    `datakey:"ids" datasep:";" validate:"required,dive,int,min=0"`
+	}
+	testdata := []struct {
+		m        map[string]string
+		failures []string
+	}{
+		{
+			map[string]string{
+				"tags": "ab,cd",
+				"ids":  "1;2;3",
+			},
+			[]string{},
+		},
+		{
+			map[string]string{
+				"tags": "ab,c,de,fg",
+				"ids":  "1;-2;a",
+			},
+			[]string{"Tags", "Tags[1]", "IDs[1]", "IDs[2]", "IDs[2]"},
+		},
+		{
+			map[string]string{
+				"tags": "ab",
+			},
+			[]string{"IDs"},
+		},
+	}
+
+	v := New()
+	v.SetCollectAllErrors(true)
+	for i, td := range testdata {
+		t.Run("TestValidator_checkRules10_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.checkRules(td.m, nil, reflect.ValueOf(&MyStruct{}).Elem())
+			failures, _ := err.(ValidationErrors)
+			if len(failures) != len(td.failures) {
+				t.Fatal()
+			}
+			for index, failure := range failures {
+				if failure.Field != td.failures[index] {
+					t.Error()
+				}
+			}
+		})
+	}
+}
+
+func TestValidator_checkRules11(t *testing.T) {
+	type MyStruct struct {
+		A string `datakey:"a" validate:"dive,min=1"`
+	}
+
+	v := New()
+	err := v.checkRules(map[string]string{"a": "1"}, nil, reflect.ValueOf(&MyStruct{}).Elem())
+	if err == nil {
+		t.Error()
+	}
+}
+
+func TestValidator_checkRules12(t *testing.T) {
+	type Pattern struct {
+		A string `datakey:"a" validate:"pattern=unknown"`
+		B string `datakey:"b" validate:"required"`
+	}
+	type Regex struct {
+		A string `datakey:"a" validate:"regex=[a-"`
+		B string `datakey:"b" validate:"required"`
+	}
+	type Bounds struct {
+		A int    `datakey:"a" validate:"min=x"`
+		B string `datakey:"b" validate:"required"`
+	}
+	type Condition struct {
+		A string `datakey:"a" validate:"required_if=b"`
+		B string `datakey:"b" validate:"required"`
+	}
+	type Valid struct {
+		A string `datakey:"a" validate:"pattern=sku"`
+		B string `datakey:"b" validate:"required"`
+	}
+
+	testData := []struct {
+		i           interface{}
+		noErrorFlag bool
+	}{
+		{&Pattern{}, false},
+		{&Regex{}, false},
+		{&Bounds{}, false},
+		{&Condition{}, false},
+		{&Valid{}, true},
+	}
+
+	v := New()
+	v.SetCollectAllErrors(true)
+	_ = v.RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$")
+	for i, td := range testData {
+		t.Run("TestValidator_checkRules12_"+strconv.Itoa(i), func(t *testing.T) {
+			//The misconfigured rules are reported as plain errors, even if their keys are absent from the map
+			err := v.checkRules(map[string]string{"c": "x"}, nil, reflect.ValueOf(td.i).Elem())
+			_, isFailures := err.(ValidationErrors)
+			_, isFieldErr := err.(*FieldError)
+			if td.noErrorFlag != isFailures || isFieldErr || err == nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestValidator_Concurrency(t *testing.T) {
+	type Level string
+	type Item struct {
+		SKU string `datakey:"sku" validate:"required,pattern=sku"`
+	}
+	type MyStruct struct {
+		A     string            `datakey:"a" validate:"required,custom" transform:"trim,custom"`
+		B     int               `datakey:"b" validate:"int,between=1|10" default:"5"`
+		C     Level             `datakey:"c"`
+		D     time.Time         `datakey:"d" validate:"time"`
+		E     time.Time         `datakey:"e" validate:"time,gtfield=d,value"`
+		Items []Item            `datakey:"items"`
+		Tags  map[string]string `datakey:"tag" validate:"dive,custom"`
+	}
+
+	m := map[string]string{"a": " value ", "c": "high", "d": "2019-08-21T09:00:00Z", "e": "2019-08-22T09:00:00Z",
+		"items[0].sku": "ABC-1234", "tag.env": "prod"}
+	rule := func(mapKey string, m map[string]string, params ...string) error { return nil }
+	valueRule := func(value reflect.Value, lookup FieldLookup, params ...string) error { return nil }
+	transformer := func(value string, params ...string) (string, error) { return value, nil }
+	converter := func(value string, params ...string) (interface{}, error) { return value, nil }
+
+	v := New()
+	_ = v.RegisterRule("custom", rule)
+	_ = v.RegisterValueRule("value", valueRule)
+	_ = v.RegisterTransformer("custom", transformer)
+	_ = v.RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$")
+	_ = v.RegisterEnum(reflect.TypeOf(Level("")).String(), "low", "high")
+
+	registrations := []func() error{
+		func() error { return v.RegisterRule("custom", rule) },
+		func() error { return v.RegisterValueRule("value", valueRule) },
+		func() error { return v.RegisterTransformer("custom", transformer) },
+		func() error { return v.RegisterConverter("other.Type", converter) },
+		func() error { return v.RegisterKindConverter(reflect.Complex64, converter) },
+		func() error { return v.RegisterPattern("sku", "^[A-Z]{3}-[0-9]{4}$") },
+		func() error { return v.RegisterEnum(reflect.TypeOf(Level("")).String(), "low", "high") },
+		func() error { v.SetCollectAllErrors(false); return nil },
+		func() error { v.SetStrictValues(false); return nil },
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				s := MyStruct{}
+				err := v.ValidateAndInit(m, &s)
+				if err == nil && (s.A != "value" || s.B != 5 || s.C != "high" || len(s.Items) != 1) {
+					err = fmt.Errorf("unexpected result: %+v", s)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	for worker := 0; worker < 2; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				if err := registrations[i%len(registrations)](); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestValidator_Validate(t *testing.T) {
+	type MyStruct struct {
+		A int       `datakey:"a" validate:"required,int,between=1|10"`
+		B string    `datakey:"b" validate:"required,oneof=x|y" default:"x" transform:"lower"`
+		C time.Time `datakey:"c" validate:"time"`
+		D time.Time `datakey:"d" validate:"time,gtfield=c"`
+	}
+
+	testData := []struct {
+		m           map[string]string
+		i           interface{}
+		noErrorFlag bool
+	}{
+		{map[string]string{"a": "1"}, &MyStruct{}, true},
+		{map[string]string{"a": "1", "b": "Y"}, &MyStruct{}, true},
+		{map[string]string{"a": "1", "b": "z"}, &MyStruct{}, false},
+		{map[string]string{"a": "11"}, &MyStruct{}, false},
+		{map[string]string{}, &MyStruct{}, false},
+		{map[string]string{"a": "1", "c": "2019-08-22T09:00:00Z", "d": "2019-08-21T09:00:00Z"}, &MyStruct{}, true},
+		{map[string]string{"a": "1"}, MyStruct{}, false},
+	}
+
+	v := New()
+	for i, td := range testData {
+		t.Run("TestValidator_Validate_"+strconv.Itoa(i), func(t *testing.T) {
+			err := v.Validate(td.m, td.i)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if s, ok := td.i.(*MyStruct); ok && !reflect.DeepEqual(*s, MyStruct{}) {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_Init(t *testing.T) {
+	type MyStruct struct {
+		A int    `datakey:"a" validate:"required,between=1|10"`
+		B string `datakey:"b" validate:"oneof=x|y" default:"x" transform:"lower"`
+		C int    `datakey:"c"`
+	}
+
+	testData := []struct {
+		m           map[string]string
+		expected    MyStruct
+		noErrorFlag bool
+	}{
+		{map[string]string{"a": "1"}, MyStruct{A: 1, B: "x", C: 7}, true},
+		{map[string]string{"a": "11", "b": "Z"}, MyStruct{A: 11, B: "z", C: 7}, true},
+		{map[string]string{"c": "8"}, MyStruct{B: "x", C: 8}, true},
+		{map[string]string{"a": "1", "c": "x"}, MyStruct{C: 7}, false},
+	}
+
+	v := New()
+	for i, td := range testData {
+		t.Run("TestValidator_Init_"+strconv.Itoa(i), func(t *testing.T) {
+			s := MyStruct{C: 7}
+			err := v.Init(td.m, &s)
+			if td.noErrorFlag && err != nil {
+				t.Error()
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+			if s != td.expected {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValidator_Check(t *testing.T) {
+	type MyStruct struct {
+		A int `datakey:"a" validate:"required,int"`
+	}
+
+	v := New()
+	schema := v.MustCompile(&MyStruct{})
+	if err := v.Check(map[string]string{"a": "1"}, schema); err != nil {
+		t.Error()
+	}
+	if err := v.Check(map[string]string{"a": "x"}, schema); err == nil {
+		t.Error()
+	}
+	if err := v.Check(map[string]string{"a": "1"}, nil); err == nil {
+		t.Error()
+	}
+	if err := New().Check(map[string]string{"a": "1"}, schema); err == nil {
+		t.Error()
+	}
+}
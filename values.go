@@ -0,0 +1,146 @@
+//This file contains the support for multi-value inputs, like query strings and forms (url.Values) or headers
+//(http.Header), where every key is linked to a list of values
+//
+//The multi-value input is flattened to the map used by the rules and the converters:
+//* the keys linked to scalar fields (including the fields of the elements of the slices of sub structs and the entries
+//  of the map fields) keep their first value; in strict mode a key with several values is a failure
+//* the keys linked to slice and array fields keep all their values, each one as an element of the field (see
+//  elementLists); the map value is the list of elements joined using the field separator
+//* the keys with no values are dropped, so they are seen as absent (e.g. by the "required" rule)
+
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//The elements of the keys linked to slice and array fields, provided as separate values by a multi-value input
+//They are kept apart from the map data, where the elements are joined using the field separator, so that a value
+//containing the separator (e.g. "a,b") stays a single element; the rules, the transformers and the converters use them
+//instead of splitting the map value
+type elementLists map[string][]string
+
+//Multi-value version of ValidateAndInit, accepting url.Values, http.Header or any other map[string][]string
+//Scalar fields take the first value of their key and slice fields take every value as an element, without splitting
+//it, so rules like "required" and "minlen" see the number of values provided
+//If the Validator is strict (see SetStrictValues), providing several values for a scalar field is a failure
+func (v *Validator) ValidateAndInitValues(values map[string][]string, i interface{}) error {
+	t, err := v.target(i)
+	if err != nil {
+		return err
+	}
+
+	m, lists, failures, err := v.flattenValues(values, t.Type())
+	if err != nil {
+		return err
+	}
+
+	return v.validateAndInit(m, lists, t, failures)
+}
+
+//Configures how the Validator reacts to several values provided for a scalar field by ValidateAndInitValues
+//By default the first value is used; if "strict" is true, the field fails with the "single" rule
+func (v *Validator) SetStrictValues(strict bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	v.strictValues = strict
+}
+
+//Flattens a multi-value input to a map with a single value per key, based on the fields of the struct type "t", and
+//to the lists of elements of the slice and array fields; a single empty value is an empty list, like an empty map value
+//Returns the failures found while flattening (the scalar fields with several values, in strict mode); if the
+//Validator does not collect all the errors, the first failure is returned as error
+func (v *Validator) flattenValues(values map[string][]string, t reflect.Type) (map[string]string, elementLists,
+	ValidationErrors, error) {
+	m := make(map[string]string, len(values))
+	lists := elementLists{}
+	failures := ValidationErrors{}
+	v.mutex.RLock()
+	strictValues := v.strictValues
+	v.mutex.RUnlock()
+
+	for key, keyValues := range values {
+		if len(keyValues) > 0 {
+			m[key] = keyValues[0]
+		}
+	}
+
+	//The keys that are not linked to a field keep their first value, since they can still be used by the rules
+	for _, kf := range v.valueKeys(m, t) {
+		keyValues := values[kf.key]
+		switch {
+		case v.isElementList(kf.field.Type):
+			//The values are copied, since the transformers change the elements in place
+			lists[kf.key] = []string{}
+			if len(keyValues) > 1 || keyValues[0] != "" {
+				lists[kf.key] = append(lists[kf.key], keyValues...)
+			}
+			m[kf.key] = strings.Join(keyValues, fieldSeparator(kf.field))
+		case strictValues && len(keyValues) > 1:
+			fieldErr := &FieldError{Field: kf.path, Key: kf.key, Rule: ruleSingle, Value: keyValues[1],
+				Err: fmt.Errorf("%d values provided for a single value field", len(keyValues))}
+			if err := v.reportFailure(fieldErr, &failures); err != nil {
+				return nil, nil, nil, err
+			}
+		}
+	}
+
+	return m, lists, failures, nil
+}
+
+//Returns the elements of the map value of a key linked to a slice or array field: the values provided separately for
+//the key, if any, otherwise the map value split using the separator (see splitElements)
+func (l elementLists) split(key string, value string, separator string) []string {
+	if elements, ok := l[key]; ok {
+		return elements
+	}
+
+	return splitElements(value, separator)
+}
+
+//Lists the keys of the map data that are linked to a value of the struct type "t", sorted by key: the keys of the
+//fields, of the fields of the elements of the slices of sub structs (e.g. "items[0].sku") and of the entries of the
+//map fields (e.g. "label.env"); the entries are described by the element type of their map field
+//The keys of the slices of sub structs and of the map fields themselves are not listed, since they have no value
+func (v *Validator) valueKeys(m map[string]string, t reflect.Type) []keyField {
+	keys := make([]keyField, 0, len(m))
+	_ = v.walkFields(m, t, structPosition{parents: []reflect.Type{t}}, func(fp fieldPlan, position structPosition) error {
+		mapKey := position.key(fp.field)
+		path := fieldPath(position.path, fp.field.Name)
+		switch {
+		case mapKey == "" || fp.isStructList:
+		case fp.isMap:
+			for _, entry := range findEntries(m, mapKey) {
+				keys = append(keys, keyField{key: entry.key, path: fmt.Sprintf("%s[%s]", path, entry.name),
+					field: elementField(fp.field)})
+			}
+		default:
+			if _, ok := m[mapKey]; ok {
+				keys = append(keys, keyField{key: mapKey, path: path, field: fp.field})
+			}
+		}
+		return nil
+	})
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].key < keys[j].key
+	})
+
+	return keys
+}
+
+//Checks if the values of a type are built from a list of elements: slices and arrays (or pointers to them) that
+//have no converter of their own
+func (v *Validator) isElementList(t reflect.Type) bool {
+	for {
+		if _, _, ok := v.resolveConverter(t); ok {
+			return false
+		}
+		if t.Kind() != reflect.Ptr {
+			return isSequence(t)
+		}
+		t = t.Elem()
+	}
+}
@@ -0,0 +1,214 @@
+package validator
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestValues_flattenValues(t *testing.T) {
+	type InnerStruct struct {
+		C []int `datakey:"c" datasep:";"`
+	}
+	type Item struct {
+		SKU  string   `datakey:"sku"`
+		Tags []string `datakey:"tags"`
+	}
+	type MyStruct struct {
+		A      string              `datakey:"a"`
+		B      []string            `datakey:"b"`
+		IS     InnerStruct
+		Items  []Item              `datakey:"items"`
+		Labels map[string]string   `datakey:"label"`
+		Ports  map[string][]string `datakey:"port"`
+	}
+	testdata := []struct {
+		in          map[string][]string
+		strict      bool
+		out         map[string]string
+		failures    int
+		noErrorFlag bool
+	}{
+		{
+			map[string][]string{
+				"a": {"1", "2"},
+				"b": {"x", "y,z"},
+				"c": {"1", "2"},
+				"d": {"q", "w"},
+				"e": {},
+			},
+			false,
+			map[string]string{
+				"a": "1",
+				"b": "x,y,z",
+				"c": "1;2",
+				"d": "q",
+			},
+			0,
+			true,
+		},
+		{
+			map[string][]string{
+				"a": {"1", "2"},
+				"b": {"x", "y"},
+				"d": {"q", "w"},
+			},
+			true,
+			map[string]string{
+				"a": "1",
+				"b": "x,y",
+				"d": "q",
+			},
+			1,
+			true,
+		},
+		{
+			map[string][]string{
+				"items[0].sku":  {"A", "B"},
+				"items[0].tags": {"x", "y"},
+				"label.env":     {"prod", "dev"},
+				"port[http]":    {"80", "8080"},
+				"items":         {"q", "w"},
+			},
+			true,
+			map[string]string{
+				"items[0].sku":  "A",
+				"items[0].tags": "x,y",
+				"label.env":     "prod",
+				"port[http]":    "80,8080",
+				"items":         "q",
+			},
+			2,
+			true,
+		},
+		{
+			map[string][]string{
+				"a": {},
+				"b": {""},
+			},
+			true,
+			map[string]string{
+				"b": "",
+			},
+			0,
+			true,
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestFlattenValues_"+strconv.Itoa(i), func(t *testing.T) {
+			v := New()
+			v.SetCollectAllErrors(true)
+			v.SetStrictValues(td.strict)
+			m, lists, failures, err := v.flattenValues(td.in, reflect.TypeOf(MyStruct{}))
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(m, td.out) || len(failures) != td.failures) {
+				t.Error()
+			}
+			//The lists keep the values of the slice fields apart, even when they contain the separator
+			if values, ok := td.in["b"]; ok && len(values) > 0 && values[0] != "" &&
+				!reflect.DeepEqual(lists.split("b", m["b"], ","), values) {
+				t.Error(lists)
+			} else if !td.noErrorFlag && err == nil {
+				t.Error()
+			}
+		})
+	}
+}
+
+func TestValues_ValidateAndInitValues(t *testing.T) {
+	type MyStruct struct {
+		Page int      `datakey:"page" validate:"int,min=1"`
+		Tags []string `datakey:"tag" validate:"required,minlen=2,dive,maxlen=3" transform:"trim"`
+		IDs  []int    `datakey:"ids"`
+	}
+	testdata := []struct {
+		in          url.Values
+		strict      bool
+		out         MyStruct
+		noErrorFlag bool
+	}{
+		{
+			url.Values{"page": {"2", "3"}, "tag": {"a", "b"}},
+			false,
+			MyStruct{Page: 2, Tags: []string{"a", "b"}},
+			true,
+		},
+		{
+			url.Values{"page": {"2", "3"}, "tag": {"a", "b"}},
+			true,
+			MyStruct{},
+			false,
+		},
+		{
+			url.Values{"tag": {"a"}},
+			false,
+			MyStruct{},
+			false,
+		},
+		{
+			url.Values{"tag": {}},
+			false,
+			MyStruct{},
+			false,
+		},
+		{
+			url.Values{"tag": {"a", "qwerty"}},
+			false,
+			MyStruct{},
+			false,
+		},
+		{
+			url.Values{"tag": {"a,b", " c "}},
+			false,
+			MyStruct{Tags: []string{"a,b", "c"}},
+			true,
+		},
+		{
+			url.Values{"tag": {"a,b"}},
+			false,
+			MyStruct{},
+			false,
+		},
+		{
+			url.Values{"tag": {"a", "b"}, "ids": {""}},
+			false,
+			MyStruct{Tags: []string{"a", "b"}, IDs: []int{}},
+			true,
+		},
+	}
+
+	for i, td := range testdata {
+		t.Run("TestValidateAndInitValues_"+strconv.Itoa(i), func(t *testing.T) {
+			v := New()
+			v.SetStrictValues(td.strict)
+			s := MyStruct{}
+			err := v.ValidateAndInitValues(td.in, &s)
+			if td.noErrorFlag && (err != nil || !reflect.DeepEqual(s, td.out)) {
+				t.Error()
+			} else if !td.noErrorFlag && (err == nil || !reflect.DeepEqual(s, td.out)) {
+				t.Error()
+			}
+		})
+	}
+
+	v := New()
+	if v.ValidateAndInitValues(url.Values{}, MyStruct{}) == nil {
+		t.Error()
+	}
+
+	//The fields of the elements of the slices of sub structs are scalar fields too
+	type Item struct {
+		SKU string `datakey:"sku"`
+	}
+	type Order struct {
+		Items []Item `datakey:"items"`
+	}
+	v.SetStrictValues(true)
+	var fieldErr *FieldError
+	err := v.ValidateAndInitValues(url.Values{"items[0].sku": {"A", "B"}}, &Order{})
+	if !errors.As(err, &fieldErr) || fieldErr.Rule != ruleSingle || fieldErr.Field != "Items[0].SKU" {
+		t.Error(err)
+	}
+}